@@ -1,11 +1,10 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
-	"github.com/LavenderBridge/spaced-repetition/internal/db"
 	"github.com/LavenderBridge/spaced-repetition/internal/models"
 	"github.com/spf13/cobra"
 )
@@ -20,24 +19,27 @@ var addCmd = &cobra.Command{
 	Use:   "add [name] [difficulty 1-5]",
 	Short: "Add a new problem to track",
 	Args:  cobra.ExactArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
 		diffStr := args[1]
 
 		difficulty := 0
 		fmt.Sscanf(diffStr, "%d", &difficulty)
 		if difficulty < 1 || difficulty > 5 {
-			fmt.Println("❌ Difficulty must be between 1 and 5")
-			return
+			return errors.New("difficulty must be between 1 and 5")
 		}
 
-		store, err := db.NewStore()
+		store, err := openStore(cmd.Context())
 		if err != nil {
-			fmt.Println("❌ Database error:", err)
-			return
+			return fmt.Errorf("database error: %w", err)
 		}
 		defer store.Close()
 
+		sched, err := loadScheduler(store)
+		if err != nil {
+			return fmt.Errorf("error loading scheduler: %w", err)
+		}
+
 		// Parse tags
 		var tags []models.Tag
 		if addTags != "" {
@@ -55,15 +57,17 @@ var addCmd = &cobra.Command{
 			Tags:       tags,
 		}
 
-		// Initialize SM-2 values
-		problem = algorithm.InitProblem(problem, 0)
+		// Initialize scheduling values
+		problem = sched.Init(problem, appClock.Now())
 
-		if err := store.AddProblem(problem); err != nil {
-			fmt.Println("❌ Error adding problem:", err)
-			return
+		problem.ID, err = store.AddProblem(problem)
+		if err != nil {
+			return fmt.Errorf("error adding problem: %w", err)
 		}
-
-		fmt.Printf("✅ Added '%s' (Next review: %s)\n", name, problem.NextReview.Format("2006-01-02"))
+		problem.Retrievability = sched.Retrievability(problem, appClock.Now())
+		return emit(problemRow(problem), func() {
+			fmt.Printf("✅ Added '%s' (Next review: %s)\n", name, store.Calendar().Date(problem.NextReview))
+		})
 	},
 }
 
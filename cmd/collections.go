@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var collectionsForce bool
+
+var collectionsCmd = &cobra.Command{
+	Use:   "collections",
+	Short: "Manage named collections of problems",
+	Long: `Collections are separate databases, e.g. one for LeetCode, one for system design
+and one for SQL practice. Pick one for a command with --collection, or set
+"collection = name" in ~/.recall/config to change the one used by default.`,
+}
+
+var collectionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List collections",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := db.Collections()
+		if err != nil {
+			return fmt.Errorf("error listing collections: %w", err)
+		}
+		active, err := databasePath()
+		if err != nil {
+			return err
+		}
+
+		rows := make([]collection, 0, len(names))
+		for _, name := range names {
+			path, err := db.CollectionPath(name)
+			if err != nil {
+				return err
+			}
+			c := collection{Name: name, Path: path, Active: path == active}
+			if _, err := os.Stat(path); err == nil {
+				count, err := db.CountProblems(path)
+				if err != nil {
+					return fmt.Errorf("error reading collection %s: %w", name, err)
+				}
+				c.Problems = &count
+			}
+			rows = append(rows, c)
+		}
+
+		return emit(rows, func() {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "\tCollection\tProblems\tPath")
+			fmt.Fprintln(w, "\t----------\t--------\t----")
+			for _, c := range rows {
+				marker := ""
+				if c.Active {
+					marker = "*"
+				}
+				count := "-"
+				if c.Problems != nil {
+					count = fmt.Sprint(*c.Problems)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, c.Name, count, c.Path)
+			}
+			w.Flush()
+		})
+	},
+}
+
+var collectionsCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create an empty collection",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		exists, err := db.CollectionExists(name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("collection '%s' already exists", name)
+		}
+		path, err := db.CollectionPath(name)
+		if err != nil {
+			return err
+		}
+		store, err := openRepository(cmd.Context(), path)
+		if err != nil {
+			return fmt.Errorf("error creating collection: %w", err)
+		}
+		store.Close()
+		active, _ := databasePath()
+		none := 0
+		return emit(collection{Name: name, Path: path, Active: path == active, Problems: &none}, func() {
+			fmt.Printf("✅ Created collection '%s'. Use it with 'recall --collection %s ...'.\n", name, name)
+		})
+	},
+}
+
+var collectionsDeleteCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Short: "Delete a collection and all its problems and reviews",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		exists, err := db.CollectionExists(name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("no collection named '%s'", name)
+		}
+
+		path, err := db.CollectionPath(name)
+		if err != nil {
+			return err
+		}
+		if !collectionsForce {
+			fmt.Fprintf(messages(), "⚠️  Are you sure you want to delete collection '%s' and all its review history? (y/N): ", name)
+			reader := bufio.NewReader(os.Stdin)
+			input, _ := reader.ReadString('\n')
+			input = strings.TrimSpace(strings.ToLower(input))
+			if input != "y" && input != "yes" {
+				fmt.Fprintln(messages(), "❌ Cancelled.")
+				return nil
+			}
+		}
+		if err := db.DeleteCollection(name); err != nil {
+			return fmt.Errorf("error deleting collection: %w", err)
+		}
+		return emit(collection{Name: name, Path: path}, func() {
+			fmt.Printf("🗑️  Deleted collection '%s'.\n", name)
+		})
+	},
+}
+
+// collection is a collection as the collections commands output it.
+// Problems is null for a collection whose database was never created.
+type collection struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Active   bool   `json:"active"` // Used when no --collection or --db is given
+	Problems *int   `json:"problems"`
+}
+
+func init() {
+	rootCmd.AddCommand(collectionsCmd)
+	collectionsCmd.AddCommand(collectionsListCmd, collectionsCreateCmd, collectionsDeleteCmd)
+	collectionsDeleteCmd.Flags().BoolVarP(&collectionsForce, "force", "f", false, "Skip confirmation")
+}
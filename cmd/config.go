@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config [key] [value]",
+	Short: "Show or change settings",
+	Long: `Show all settings, show a single setting, or change one.
+Settings are stored per database. Use an empty value ("") to restore the default.`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer store.Close()
+
+		if len(args) == 0 {
+			settings := make([]setting, len(config.Options))
+			for i, o := range config.Options {
+				value, err := store.GetSetting(o.Key)
+				if err != nil {
+					return fmt.Errorf("error reading settings: %w", err)
+				}
+				settings[i] = newSetting(o, value)
+			}
+			return emit(settings, func() {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "Key\tValue\tDescription")
+				fmt.Fprintln(w, "---\t-----\t-----------")
+				for _, s := range settings {
+					value := s.Value
+					if s.IsDefault {
+						value += " (default)"
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, value, s.Description)
+				}
+				w.Flush()
+			})
+		}
+
+		opt, ok := config.Lookup(args[0])
+		if !ok {
+			return fmt.Errorf("unknown setting %q (run 'recall config' to list settings)", args[0])
+		}
+
+		if len(args) == 1 {
+			value, err := store.GetSetting(opt.Key)
+			if err != nil {
+				return fmt.Errorf("error reading settings: %w", err)
+			}
+			s := newSetting(opt, value)
+			return emit(s, func() { fmt.Println(s.Value) })
+		}
+
+		value := args[1]
+		if value != "" {
+			if err := opt.Validate(value); err != nil {
+				return err
+			}
+		}
+		if err := store.SetSetting(opt.Key, value); err != nil {
+			return fmt.Errorf("error saving setting: %w", err)
+		}
+		return emit(newSetting(opt, value), func() { fmt.Printf("✅ %s set to %q\n", opt.Key, value) })
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
+
+// setting is a setting as config outputs it, with the default filled in
+// for an empty stored value.
+type setting struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	IsDefault   bool   `json:"is_default"`
+	Default     string `json:"default"`
+	Description string `json:"description"`
+}
+
+func newSetting(o config.Option, stored string) setting {
+	s := setting{Key: o.Key, Value: stored, Default: o.Default, Description: o.Description}
+	if stored == "" {
+		s.Value, s.IsDefault = o.Default, true
+	}
+	return s
+}
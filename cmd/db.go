@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var migrateStatus bool
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the database",
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending schema migrations",
+	Long: `Apply pending schema migrations. Every command already migrates the database
+when it opens it, so this is mostly useful with --status to see which migrations have run.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer store.Close()
+
+		migrator, ok := store.(db.Migrator)
+		if !ok {
+			fmt.Println("ℹ️ This storage has no schema to migrate.")
+			return nil
+		}
+
+		if migrateStatus {
+			migrations, err := migrator.Migrations()
+			if err != nil {
+				return fmt.Errorf("error reading migrations: %w", err)
+			}
+			return emit(migrationRows(migrations), func() {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "Version\tMigration\tApplied")
+				fmt.Fprintln(w, "-------\t---------\t-------")
+				for _, m := range migrations {
+					applied := "pending"
+					if !m.AppliedAt.IsZero() {
+						applied = m.AppliedAt.Local().Format("2006-01-02 15:04")
+					}
+					fmt.Fprintf(w, "%d\t%s\t%s\n", m.Version, m.Name, applied)
+				}
+				w.Flush()
+			})
+		}
+
+		applied, err := migrator.Migrate()
+		if !structured() {
+			for _, m := range applied {
+				fmt.Printf("✅ Applied migration %d (%s)\n", m.Version, m.Name)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+		// Only the search index can be left behind, when SQLite lacks FTS5.
+		migrations, err := migrator.Migrations()
+		if err != nil {
+			return fmt.Errorf("error reading migrations: %w", err)
+		}
+		return emit(migrationRows(migrations), func() {
+			pending := false
+			for _, m := range migrations {
+				if m.AppliedAt.IsZero() {
+					fmt.Printf("ℹ️ Migration %d (%s) is pending: %v\n", m.Version, m.Name, db.ErrSearchUnavailable)
+					pending = true
+				}
+			}
+			if len(applied) == 0 && !pending {
+				fmt.Printf("✅ Database schema is up to date (version %d).\n", db.SchemaVersion)
+			}
+		})
+	},
+}
+
+// migrationOutput is a migration as db migrate outputs it, with a null
+// applied_at while it is pending.
+type migrationOutput struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+func migrationRows(migrations []db.Migration) []migrationOutput {
+	rows := make([]migrationOutput, len(migrations))
+	for i, m := range migrations {
+		rows[i] = migrationOutput{Version: m.Version, Name: m.Name}
+		if !m.AppliedAt.IsZero() {
+			rows[i].AppliedAt = &m.AppliedAt
+		}
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbMigrateCmd.Flags().BoolVar(&migrateStatus, "status", false, "Show which migrations have been applied")
+}
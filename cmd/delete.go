@@ -2,52 +2,54 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 
-	"github.com/LavenderBridge/spaced-repetition/internal/db"
 	"github.com/spf13/cobra"
 )
 
 var forceDelete bool
 
 var deleteCmd = &cobra.Command{
-	Use:   "delete [id]",
+	Use:   "delete [problem]",
 	Short: "Delete a problem",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		id, err := strconv.Atoi(args[0])
+	Long: `Delete a problem and its review history. The problem can be given by
+ID, URL slug (two-sum), exact name or part of its name.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
 		if err != nil {
-			fmt.Println("❌ Invalid ID")
-			return
+			return fmt.Errorf("database error: %w", err)
 		}
+		defer store.Close()
 
-		store, err := db.NewStore()
+		reader := bufio.NewReader(os.Stdin)
+		target, err := resolveProblem(store, strings.Join(args, " "), reader)
+		if errors.Is(err, errCancelled) {
+			fmt.Fprintln(messages(), "❌ Cancelled.")
+			return nil
+		}
 		if err != nil {
-			fmt.Println("❌ Database error:", err)
-			return
+			return err
 		}
-		defer store.Close()
 
 		if !forceDelete {
-			fmt.Printf("⚠️  Are you sure you want to delete problem %d? (y/N): ", id)
-			reader := bufio.NewReader(os.Stdin)
+			fmt.Fprintf(messages(), "⚠️  Are you sure you want to delete %q (ID %d)? (y/N): ", target.Name, target.ID)
 			input, _ := reader.ReadString('\n')
 			input = strings.TrimSpace(strings.ToLower(input))
 			if input != "y" && input != "yes" {
-				fmt.Println("❌ Cancelled.")
-				return
+				fmt.Fprintln(messages(), "❌ Cancelled.")
+				return nil
 			}
 		}
 
-		if err := store.DeleteProblem(id); err != nil {
-			fmt.Println("❌ Error deleting problem:", err)
-			return
+		if err := store.DeleteProblem(target.ID); err != nil {
+			return fmt.Errorf("error deleting problem: %w", err)
 		}
 
-		fmt.Println("✅ Problem deleted.")
+		return emit(problemRow(*target), func() { fmt.Println("✅ Problem deleted.") })
 	},
 }
 
@@ -0,0 +1,34 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// Declining to delete, or to pick one of several matches, is not an error.
+func TestDeleteCancelled(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.AddProblem(models.Problem{Name: "Two Sum", Difficulty: 1})
+	store.AddProblem(models.Problem{Name: "Two Sum II", Difficulty: 2})
+
+	for _, tt := range []struct {
+		name, stdin string
+		args        []string
+	}{
+		{"declined", "n\n", []string{"delete", "Two Sum", "--force=false"}},
+		{"no choice", "\n", []string{"delete", "two", "--force=false"}},
+		{"edit with no choice", "\n", []string{"edit", "two", "--difficulty", "5"}},
+	} {
+		out := execute(t, store, tt.stdin, append(tt.args, "--output", "table")...)
+		if !strings.Contains(out, "❌ Cancelled.") {
+			t.Errorf("%s: got output %q, want it cancelled", tt.name, out)
+		}
+	}
+	problems, _ := store.ListProblems(false)
+	if len(problems) != 2 || problems[0].Difficulty != 1 || problems[1].Difficulty != 2 {
+		t.Errorf("got problems %+v, want both unchanged", problems)
+	}
+}
@@ -2,56 +2,89 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"strings"
-	"text/tabwriter"
 
-	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+	"github.com/LavenderBridge/spaced-repetition/internal/config"
+	"github.com/LavenderBridge/spaced-repetition/internal/query"
 	"github.com/spf13/cobra"
 )
 
+var (
+	dueSort    string
+	dueReverse bool
+	dueLimit   int
+)
+
 var dueCmd = &cobra.Command{
-	Use:   "due",
+	Use:   "due [query]",
 	Short: "Show problems due for review today",
-	Run: func(cmd *cobra.Command, args []string) {
-		store, err := db.NewStore()
+	Long:  "Show problems due for review today.\n" + queryHelp,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
 		if err != nil {
-			fmt.Println("❌ Database error:", err)
-			return
+			return fmt.Errorf("database error: %w", err)
 		}
 		defer store.Close()
 
-		problems, err := store.ListProblems(true)
+		cfg, err := config.Load(store.GetSetting)
 		if err != nil {
-			fmt.Println("❌ Error listing due problems:", err)
-			return
+			return fmt.Errorf("error loading settings: %w", err)
 		}
-
-		if len(problems) == 0 {
-			fmt.Println("✅ No problems due today! Good job.")
-			return
+		q, err := problemQuery(args, query.Is("due"), dueSort, dueReverse, dueLimit, cfg)
+		if err != nil {
+			return err
+		}
+		sched, err := loadScheduler(store)
+		if err != nil {
+			return fmt.Errorf("error loading scheduler: %w", err)
+		}
+		problems, err := queryProblems(store, sched, q)
+		if err != nil {
+			return fmt.Errorf("error listing due problems: %w", err)
 		}
 
-		fmt.Printf("🔥 %d Problems due today:\n\n", len(problems))
-
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "ID\tProblem\tDiff\tNext Review\tTags")
-		fmt.Fprintln(w, "--\t-------\t----\t-----------\t----")
-
-		for _, p := range problems {
-			var tagNames []string
-			for _, t := range p.Tags {
-				tagNames = append(tagNames, t.Name)
+		return emit(problemRows(problems), func() {
+			printCountdown(cfg, store.Calendar())
+			if len(problems) == 0 {
+				if len(args) > 0 {
+					fmt.Println("✅ No matching problems due today.")
+				} else {
+					fmt.Println("✅ No problems due today! Good job.")
+				}
+				return
 			}
-			tagsStr := strings.Join(tagNames, ", ")
 
-			fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", 
-				p.ID, p.Name, p.Difficulty, p.NextReview.Format("2006-01-02"), tagsStr)
-		}
-		w.Flush()
+			if dueLimit > 0 && len(problems) == dueLimit {
+				fmt.Printf("🔥 First %d problems due today:\n\n", len(problems))
+			} else {
+				fmt.Printf("🔥 %d Problems due today:\n\n", len(problems))
+			}
+			printProblems(problems, store.Calendar(), sched.Name() == algorithm.LeitnerName)
+		})
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(dueCmd)
+	addQueryFlags(dueCmd, &dueSort, &dueReverse, &dueLimit)
+}
+
+// printCountdown shows the days left until the configured interview date.
+func printCountdown(cfg config.Config, cal clock.Calendar) {
+	deadline := cfg.Deadline()
+	if deadline.IsZero() {
+		return
+	}
+	date := cal.Date(deadline)
+	switch days := cal.DaysBetween(appClock.Now(), deadline); {
+	case days > 1:
+		fmt.Printf("🎯 Interview in %d days (%s): every problem gets at least %d more reviews before then.\n\n", days, date, cfg.InterviewReviews)
+	case days == 1:
+		fmt.Printf("🎯 Interview tomorrow (%s). Last review session!\n\n", date)
+	case days == 0:
+		fmt.Printf("🎯 Interview today (%s). Good luck!\n\n", date)
+	default:
+		fmt.Printf("🎯 Interview date %s has passed. Clear it with 'recall config interview_date \"\"'.\n\n", date)
+	}
 }
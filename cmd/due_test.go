@@ -0,0 +1,59 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// --now is read in the configured timezone, and due counts the study day
+// up to the rollover hour.
+func TestDueNow(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := db.NewMemoryStore()
+	store.SetSetting("timezone", "Asia/Tokyo")
+	store.SetSetting("day_start_hour", "4")
+	next := time.Date(2026, 3, 11, 3, 0, 0, 0, tokyo) // Still March 10th's study day
+	store.AddProblem(models.Problem{Name: "Two Sum", Difficulty: 3, Interval: 10, EaseFactor: 2.5,
+		LastReviewed: next.AddDate(0, 0, -10), NextReview: next, State: models.StateReview})
+
+	for _, tt := range []struct {
+		now  string
+		want int
+	}{
+		{"2026-03-09 23:00", 0},
+		{"2026-03-10", 1},
+		{"2026-03-11 03:59", 1},
+		{"2026-03-11T03:00:00Z", 1}, // Noon in Tokyo
+	} {
+		out := execute(t, store, "", "--now", tt.now, "due", "--output", "json")
+		var due []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(out), &due); err != nil {
+			t.Fatalf("due --now %s: %v in %q", tt.now, err, out)
+		}
+		if len(due) != tt.want {
+			t.Errorf("due --now %s: got %v, want %d problems", tt.now, due, tt.want)
+		}
+	}
+
+	execute(t, store, "\n4\n\n", "--now", "2026-03-11 01:00", "review", "Two Sum", "--output", "table")
+	p, err := store.GetProblem("Two Sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reviewed := time.Date(2026, 3, 11, 1, 0, 0, 0, tokyo); !p.LastReviewed.Equal(reviewed) || !p.NextReview.After(reviewed.AddDate(0, 0, 10)) {
+		t.Errorf("review --now: got last reviewed %v and next %v, want reviewed at %v", p.LastReviewed, p.NextReview, reviewed)
+	}
+	out := execute(t, store, "", "--now", "2026-03-11 01:00", "due", "--output", "json")
+	if out != "[]\n" {
+		t.Errorf("due after reviewing: got %q, want []", out)
+	}
+}
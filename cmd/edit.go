@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
-	"strconv"
+	"os"
 	"strings"
 
-	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
 	"github.com/LavenderBridge/spaced-repetition/internal/models"
 	"github.com/spf13/cobra"
 )
@@ -16,52 +18,31 @@ var (
 	editNotes      string
 	editTags       string
 	editDifficulty int
+	editSuspend    bool
+	editUnsuspend  bool
+	editReseed     bool
 )
 
 var editCmd = &cobra.Command{
-	Use:   "edit [id]",
+	Use:   "edit [problem]",
 	Short: "Edit a problem details",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		id, err := strconv.Atoi(args[0])
+	Long: `Edit a problem's details. The problem can be given by ID, URL slug
+(two-sum), exact name or part of its name.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
 		if err != nil {
-			fmt.Println("❌ Invalid ID")
-			return
-		}
-
-		store, err := db.NewStore()
-		if err != nil {
-			fmt.Println("❌ Database error:", err)
-			return
+			return fmt.Errorf("database error: %w", err)
 		}
 		defer store.Close()
 
-		// Get existing
-		// We need a GetProblemByID, but currently we only have GetProblemByName.
-		// Let's rely on List and filtering for now or add GetProblemByID.
-		// Actually, let's implement GetProblemByID nicely or just List and find.
-		// Listing all is inefficient but fine for CLI MVP. 
-		// BETTER: Implementing GetProblemByID in DB is cleaner. 
-		// Wait, I can't restart DB implementation easily. 
-		// Let's use ListProblems and find the ID.
-		
-		problems, err := store.ListProblems(false)
-		if err != nil {
-			fmt.Println("❌ Error fetching problems:", err)
-			return
+		target, err := resolveProblem(store, strings.Join(args, " "), bufio.NewReader(os.Stdin))
+		if errors.Is(err, errCancelled) {
+			fmt.Fprintln(messages(), "❌ Cancelled.")
+			return nil
 		}
-		
-		var target *models.Problem
-		for _, p := range problems {
-			if p.ID == id {
-				target = &p
-				break
-			}
-		}
-
-		if target == nil {
-			fmt.Println("❌ Problem not found with ID:", id)
-			return
+		if err != nil {
+			return err
 		}
 
 		// Apply updates
@@ -76,8 +57,7 @@ var editCmd = &cobra.Command{
 		}
 		if cmd.Flags().Changed("difficulty") {
 			if editDifficulty < 1 || editDifficulty > 5 {
-				fmt.Println("❌ Difficulty must be between 1 and 5")
-				return
+				return errors.New("difficulty must be between 1 and 5")
 			}
 			target.Difficulty = editDifficulty
 		}
@@ -93,13 +73,44 @@ var editCmd = &cobra.Command{
 			target.Tags = newTags
 		}
 
-		// Save
-		if err := store.UpdateProblemDetails(*target); err != nil {
-			fmt.Println("❌ Error updating problem:", err)
-			return
+		if editSuspend {
+			target.Suspended = true
+		}
+		if editUnsuspend {
+			target.Suspended = false
+		}
+
+		reseed := editReseed && cmd.Flags().Changed("difficulty")
+		if reseed && target.State != models.StateNew {
+			fmt.Fprintln(messages(), "ℹ️ Schedule kept: the problem has already been reviewed.")
+			reseed = false
+		}
+		var sched algorithm.Scheduler
+		if reseed || structured() {
+			sched, err = loadScheduler(store)
+			if err != nil {
+				return fmt.Errorf("error loading scheduler: %w", err)
+			}
+		}
+		if reseed {
+			// Restart the schedule from when the problem was added.
+			*target = sched.Init(*target, target.LastReviewed)
 		}
 
-		fmt.Println("✅ Problem updated successfully!")
+		// Save details and schedule together
+		if err := store.SaveProblem(*target); err != nil {
+			return fmt.Errorf("error updating problem: %w", err)
+		}
+
+		if sched != nil {
+			target.Retrievability = sched.Retrievability(*target, appClock.Now())
+		}
+		return emit(problemRow(*target), func() {
+			fmt.Println("✅ Problem updated successfully!")
+			if reseed {
+				fmt.Printf("🌱 Schedule re-seeded for difficulty %d (next review: %s).\n", target.Difficulty, store.Calendar().Date(target.NextReview))
+			}
+		})
 	},
 }
 
@@ -111,4 +122,8 @@ func init() {
 	editCmd.Flags().StringVar(&editNotes, "notes", "", "New notes")
 	editCmd.Flags().IntVar(&editDifficulty, "difficulty", 0, "New difficulty (1-5)")
 	editCmd.Flags().StringVar(&editTags, "tags", "", "Comma-separated tags (replaces existing)")
+	editCmd.Flags().BoolVar(&editSuspend, "suspend", false, "Suspend the problem so it is never due")
+	editCmd.Flags().BoolVar(&editUnsuspend, "unsuspend", false, "Make a suspended problem due again")
+	editCmd.Flags().BoolVar(&editReseed, "reseed", false, "With --difficulty, restart the schedule of a never-reviewed problem from the new difficulty")
+	editCmd.MarkFlagsMutuallyExclusive("suspend", "unsuspend")
 }
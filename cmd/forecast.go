@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// forecastRuns is the number of simulations averaged for a forecast.
+const forecastRuns = 20
+
+var (
+	forecastDays      int
+	forecastNewPerDay int
+)
+
+var forecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Project how many problems will be due each day",
+	Long: `Simulate future reviews with the current scheduler, assuming you rate problems
+the way you have in the past, and chart how many problems will be due each day.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if forecastDays < 1 {
+			return errors.New("--days must be at least 1")
+		}
+		if forecastNewPerDay < 0 {
+			return errors.New("--new-per-day cannot be negative")
+		}
+
+		store, err := openStore(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer store.Close()
+
+		sched, err := loadScheduler(store)
+		if err != nil {
+			return fmt.Errorf("error loading scheduler: %w", err)
+		}
+
+		problems, err := store.ListProblems(false)
+		if err != nil {
+			return fmt.Errorf("error fetching problems: %w", err)
+		}
+		reviews, err := store.ListReviews()
+		if err != nil {
+			return fmt.Errorf("error fetching reviews: %w", err)
+		}
+		for i := len(reviews) - 1; i >= 0; i-- {
+			if reviews[i].Notes == db.LegacyReviewNote {
+				reviews = append(reviews[:i], reviews[i+1:]...)
+			}
+		}
+
+		cal := store.Calendar()
+		now := appClock.Now()
+		forecast := algorithm.Forecast{
+			Days:      forecastDays,
+			NewPerDay: forecastNewPerDay,
+			Qualities: algorithm.QualityDistribution(reviews),
+			Runs:      forecastRuns,
+			Calendar:  cal,
+			Rand:      rand.New(rand.NewSource(1)),
+		}
+		due := forecast.Run(sched, problems, now)
+
+		peak, total := 0.0, 0.0
+		for _, n := range due {
+			peak = math.Max(peak, n)
+			total += n
+		}
+
+		days := make([]forecastDay, len(due))
+		for day, n := range due {
+			days[day] = forecastDay{cal.DayStart(now).AddDate(0, 0, day).Format("2006-01-02"), math.Round(n*100) / 100}
+		}
+
+		return emit(days, func() {
+			fmt.Printf("🔮 Forecast for the next %d days", forecastDays)
+			if forecastNewPerDay > 0 {
+				fmt.Printf(" (+%d new problems/day)", forecastNewPerDay)
+			}
+			fmt.Println()
+			fmt.Println()
+
+			const barWidth = 40
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Date\tDue\t")
+			fmt.Fprintln(w, "----\t---\t")
+			for day, n := range due {
+				date := cal.DayStart(now).AddDate(0, 0, day)
+				bar := ""
+				if peak > 0 {
+					bar = strings.Repeat("█", int(math.Round(n/peak*barWidth)))
+				}
+				fmt.Fprintf(w, "%s\t%.1f\t%s\n", date.Format("2006-01-02 Mon"), n, bar)
+			}
+			w.Flush()
+
+			fmt.Printf("\nTotal: %.0f reviews, average %.1f/day, peak %.0f\n", total, total/float64(len(due)), peak)
+		})
+	},
+}
+
+// forecastDay is the expected number of problems due on a study day.
+type forecastDay struct {
+	Date string  `json:"date"`
+	Due  float64 `json:"due"`
+}
+
+func init() {
+	rootCmd.AddCommand(forecastCmd)
+	forecastCmd.Flags().IntVarP(&forecastDays, "days", "d", 30, "Number of days to forecast")
+	forecastCmd.Flags().IntVar(&forecastNewPerDay, "new-per-day", 0, "Assume this many new problems are added each day")
+}
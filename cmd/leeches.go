@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/config"
+	"github.com/LavenderBridge/spaced-repetition/internal/query"
+	"github.com/spf13/cobra"
+)
+
+var leechesCmd = &cobra.Command{
+	Use:   "leeches",
+	Short: "List problems you keep forgetting",
+	Long: `List problems that have lapsed at least leech_threshold times or are tagged as leeches,
+most-lapsed first. These are worth re-studying from scratch rather than grinding.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer store.Close()
+
+		cfg, err := config.Load(store.GetSetting)
+		if err != nil {
+			return fmt.Errorf("error loading settings: %w", err)
+		}
+
+		sched, err := loadScheduler(store)
+		if err != nil {
+			return fmt.Errorf("error loading scheduler: %w", err)
+		}
+		leeches, err := queryProblems(store, sched, query.Query{
+			Filter:         query.Is("leech"),
+			Sort:           "lapses",
+			LeechThreshold: cfg.LeechThreshold,
+		})
+		if err != nil {
+			return fmt.Errorf("error listing problems: %w", err)
+		}
+
+		return emit(problemRows(leeches), func() {
+			if len(leeches) == 0 {
+				fmt.Println("✅ No leeches. Nothing keeps slipping away!")
+				return
+			}
+			fmt.Printf("🩸 %d Leeches (threshold: %d lapses):\n\n", len(leeches), cfg.LeechThreshold)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tProblem\tLapses\tSuspended\tTags")
+			fmt.Fprintln(w, "--\t-------\t------\t---------\t----")
+			for _, p := range leeches {
+				suspended := ""
+				if p.Suspended {
+					suspended = "yes"
+				}
+				fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n",
+					p.ID, p.Name, p.Lapses, suspended, tagList(p))
+			}
+			w.Flush()
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(leechesCmd)
+}
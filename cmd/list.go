@@ -3,48 +3,164 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"text/tabwriter"
 
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+	"github.com/LavenderBridge/spaced-repetition/internal/config"
 	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/LavenderBridge/spaced-repetition/internal/query"
 	"github.com/spf13/cobra"
 )
 
+var (
+	listSort    string
+	listReverse bool
+	listLimit   int
+)
+
+// queryHelp describes the query language of list and due.
+const queryHelp = `
+Narrow the problems down with a query such as "tag:dp diff:>=4 due:<7d":
+  tag:dp              has the tag
+  name:sum, or sum    name contains the text
+  is:due              also new, learning, review, suspended or leech
+  diff:>=4            difficulty; likewise ivl: (interval), ease:, lapses: and box:
+  due:<7d             due in fewer than 7 days (0 is today, negative is overdue)
+  reviewed:>2w        last reviewed more than 2 weeks ago
+Numbers and days take =, !=, <, <=, >, >= or a range such as 2..4.
+Terms must all match; combine them with OR, negate them with NOT or -, and
+group them with parentheses: "tag:dp (diff:5 OR is:leech) -tag:easy".`
+
 var listCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List all tracked problems",
-	Run: func(cmd *cobra.Command, args []string) {
-		store, err := db.NewStore()
+	Use:   "list [query]",
+	Short: "List tracked problems",
+	Long:  "List tracked problems, soonest due first.\n" + queryHelp,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
 		if err != nil {
-			fmt.Println("❌ Database error:", err)
-			return
+			return fmt.Errorf("database error: %w", err)
 		}
 		defer store.Close()
 
-		problems, err := store.ListProblems(false)
+		cfg, err := config.Load(store.GetSetting)
 		if err != nil {
-			fmt.Println("❌ Error listing problems:", err)
-			return
+			return fmt.Errorf("error loading settings: %w", err)
+		}
+		q, err := problemQuery(args, nil, listSort, listReverse, listLimit, cfg)
+		if err != nil {
+			return err
 		}
 
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "ID\tProblem\tDiff\tNext Review\tTags")
-		fmt.Fprintln(w, "--\t-------\t----\t-----------\t----")
-
-		for _, p := range problems {
-			var tagNames []string
-			for _, t := range p.Tags {
-				tagNames = append(tagNames, t.Name)
-			}
-			tagsStr := strings.Join(tagNames, ", ")
-			
-			fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", 
-				p.ID, p.Name, p.Difficulty, p.NextReview.Format("2006-01-02"), tagsStr)
+		sched, err := loadScheduler(store)
+		if err != nil {
+			return fmt.Errorf("error loading scheduler: %w", err)
+		}
+		problems, err := queryProblems(store, sched, q)
+		if err != nil {
+			return fmt.Errorf("error listing problems: %w", err)
 		}
-		w.Flush()
+		return emit(problemRows(problems), func() {
+			if len(problems) == 0 && q.Filter != nil {
+				fmt.Println("🔎 No problems match the query.")
+				return
+			}
+			printProblems(problems, store.Calendar(), sched.Name() == algorithm.LeitnerName)
+		})
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(listCmd)
+	addQueryFlags(listCmd, &listSort, &listReverse, &listLimit)
+}
+
+// addQueryFlags adds the sorting and limit flags shared by list and due.
+func addQueryFlags(cmd *cobra.Command, sort *string, reverse *bool, limit *int) {
+	cmd.Flags().StringVarP(sort, "sort", "s", "due", "Sort by: "+strings.Join(query.SortKeys, ", "))
+	cmd.Flags().BoolVarP(reverse, "reverse", "r", false, "Reverse the sort order")
+	cmd.Flags().IntVarP(limit, "limit", "n", 0, "Show at most this many problems (0 for all)")
+}
+
+// problemQuery builds the query of list and due from the words of a query
+// given as arguments, ANDed with base unless it is nil, and the flags.
+func problemQuery(args []string, base query.Expr, sort string, reverse bool, limit int, cfg config.Config) (query.Query, error) {
+	if !slices.Contains(query.SortKeys, sort) {
+		return query.Query{}, fmt.Errorf("unknown sort key %q (use %s)", sort, strings.Join(query.SortKeys, ", "))
+	}
+	filter, err := query.Parse(strings.Join(args, " "))
+	if err != nil {
+		return query.Query{}, fmt.Errorf("invalid query: %w", err)
+	}
+	switch {
+	case base == nil:
+	case filter == nil:
+		filter = base
+	default:
+		filter = query.And{base, filter}
+	}
+	return query.Query{Filter: filter, Sort: sort, Reverse: reverse, Limit: limit, LeechThreshold: cfg.LeechThreshold}, nil
+}
+
+// queryProblems loads the problems selected by q with their predicted
+// recall. Only the scheduler can predict recall, so sorting by it happens
+// here rather than in the store.
+func queryProblems(store db.Repository, sched algorithm.Scheduler, q query.Query) ([]models.Problem, error) {
+	if q.Sort != query.SortRecall {
+		problems, err := store.QueryProblems(q)
+		if err != nil {
+			return nil, err
+		}
+		predictRecall(sched, problems)
+		return problems, nil
+	}
+
+	problems, err := store.QueryProblems(query.Query{Filter: q.Filter, LeechThreshold: q.LeechThreshold})
+	if err != nil {
+		return nil, err
+	}
+	predictRecall(sched, problems)
+	if err := query.Sort(problems, q.Sort, q.Reverse); err != nil {
+		return nil, err
+	}
+	if q.Limit > 0 && len(problems) > q.Limit {
+		problems = problems[:q.Limit]
+	}
+	return problems, nil
+}
+
+// printProblems writes the problem table shared by list and due, with a
+// Leitner box column when showBox is set.
+func printProblems(problems []models.Problem, cal clock.Calendar, showBox bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if showBox {
+		fmt.Fprintln(w, "ID\tProblem\tDiff\tBox\tNext Review\tRecall\tTags")
+		fmt.Fprintln(w, "--\t-------\t----\t---\t-----------\t------\t----")
+	} else {
+		fmt.Fprintln(w, "ID\tProblem\tDiff\tNext Review\tRecall\tTags")
+		fmt.Fprintln(w, "--\t-------\t----\t-----------\t------\t----")
+	}
+
+	for _, p := range problems {
+		tagsStr := tagList(p)
+		if showBox {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%s\t%.0f%%\t%s\n",
+				p.ID, p.Name, p.Difficulty, p.Box, cal.Date(p.NextReview), p.Retrievability*100, tagsStr)
+		} else {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%.0f%%\t%s\n",
+				p.ID, p.Name, p.Difficulty, cal.Date(p.NextReview), p.Retrievability*100, tagsStr)
+		}
+	}
+	w.Flush()
+}
+
+// predictRecall fills in each problem's current retrievability.
+func predictRecall(sched algorithm.Scheduler, problems []models.Problem) {
+	now := appClock.Now()
+	for i := range problems {
+		problems[i].Retrievability = sched.Retrievability(problems[i], now)
+	}
 }
@@ -0,0 +1,35 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+func TestListJSON(t *testing.T) {
+	store := db.NewMemoryStore()
+	next := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	store.AddProblem(models.Problem{Name: "Two Sum", Difficulty: 1, Interval: 1, EaseFactor: 2.5, NextReview: next,
+		State: models.StateReview, Tags: []models.Tag{{Name: "array"}, {Name: "hash"}}})
+	store.AddProblem(models.Problem{Name: `Coin "Change"`, Difficulty: 4, Interval: 1, EaseFactor: 2.5, NextReview: next.AddDate(0, 0, -1),
+		State: models.StateReview})
+
+	out := execute(t, store, "", "--now", "2026-03-10", "list", "--output", "json")
+	var problems []struct {
+		ID         int       `json:"id"`
+		Name       string    `json:"name"`
+		Difficulty int       `json:"difficulty"`
+		NextReview time.Time `json:"next_review"`
+		Tags       []string  `json:"tags"`
+	}
+	if err := json.Unmarshal([]byte(out), &problems); err != nil {
+		t.Fatalf("list --output json: %v in %q", err, out)
+	}
+	if got := fmt.Sprint(problems); got != `[{2 Coin "Change" 4 2026-03-09 12:00:00 +0000 UTC []} {1 Two Sum 1 2026-03-10 12:00:00 +0000 UTC [array hash]}]` {
+		t.Errorf("list --output json: got %s", got)
+	}
+}
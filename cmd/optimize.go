@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	optimizeDryRun bool
+	optimizeReset  bool
+)
+
+var optimizeCmd = &cobra.Command{
+	Use:   "optimize",
+	Short: "Fit scheduler parameters to your review history",
+	Long: `Fit the active scheduler's parameters (SM-2 ease deltas or FSRS weights)
+to your actual recall outcomes and save them for future reviews.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer store.Close()
+
+		sched, err := loadScheduler(store)
+		if err != nil {
+			return fmt.Errorf("error loading scheduler: %w", err)
+		}
+
+		if optimizeReset {
+			if err := store.SetSetting(paramsSetting(sched.Name()), ""); err != nil {
+				return fmt.Errorf("error resetting parameters: %w", err)
+			}
+			defaults, err := algorithm.New(sched.Name())
+			if err != nil {
+				return fmt.Errorf("error loading scheduler: %w", err)
+			}
+			reset := optimizeOutput{Scheduler: sched.Name(), Params: defaults.Params(), Saved: true}
+			return emit(reset, func() { fmt.Printf("✅ Reset %s parameters to defaults.\n", sched.Name()) })
+		}
+
+		if len(sched.Params()) == 0 {
+			skipped := optimizeSkipped{Scheduler: sched.Name(), Reason: "no parameters to fit"}
+			return emit(skipped, func() { fmt.Printf("ℹ️ The %s scheduler has no parameters to fit.\n", sched.Name()) })
+		}
+
+		reviews, err := store.ListReviews()
+		if err != nil {
+			return fmt.Errorf("error fetching reviews: %w", err)
+		}
+		histories := groupReviews(reviews)
+
+		before := algorithm.Evaluate(sched, histories)
+		if before.Samples < algorithm.MinFitSamples {
+			skipped := optimizeSkipped{Scheduler: sched.Name(), Reason: "not enough review history", Samples: before.Samples}
+			return emit(skipped, func() {
+				fmt.Printf("⚠️ Not enough review history to optimize (%d of %d repeat reviews needed).\n", before.Samples, algorithm.MinFitSamples)
+			})
+		}
+
+		fmt.Fprintf(messages(), "🔧 Fitting %s parameters to %d reviews...\n", sched.Name(), before.Samples)
+		fitted, after, err := algorithm.Fit(sched, histories)
+		if err != nil {
+			return fmt.Errorf("error fitting parameters: %w", err)
+		}
+
+		if !optimizeDryRun {
+			raw, err := json.Marshal(fitted.Params())
+			if err != nil {
+				return fmt.Errorf("error encoding parameters: %w", err)
+			}
+			if err := store.SetSetting(paramsSetting(sched.Name()), string(raw)); err != nil {
+				return fmt.Errorf("error saving parameters: %w", err)
+			}
+		}
+
+		out := optimizeOutput{
+			Scheduler:        sched.Name(),
+			Samples:          before.Samples,
+			ActualRetention:  before.ActualRetention,
+			CurrentRetention: before.PredictedRetention,
+			FittedRetention:  after.PredictedRetention,
+			CurrentLogLoss:   before.LogLoss,
+			FittedLogLoss:    after.LogLoss,
+			Params:           fitted.Params(),
+			Fitted:           true,
+			Saved:            !optimizeDryRun,
+		}
+		return emit(out, func() {
+			fmt.Println()
+			fmt.Printf("                      Current   Fitted\n")
+			fmt.Printf("Predicted retention:  %6.1f%%  %6.1f%%\n", before.PredictedRetention*100, after.PredictedRetention*100)
+			fmt.Printf("Log loss:             %7.4f  %7.4f\n", before.LogLoss, after.LogLoss)
+			fmt.Printf("Actual retention:     %6.1f%%\n", before.ActualRetention*100)
+			fmt.Println()
+			fmt.Printf("Parameters: %s\n", formatParams(fitted.Params()))
+
+			if optimizeDryRun {
+				fmt.Println("ℹ️ Dry run, parameters not saved.")
+			} else {
+				fmt.Println("✅ Saved fitted parameters.")
+			}
+		})
+	},
+}
+
+// optimizeOutput is the output of optimize: how well the current and the
+// fitted parameters predict recall, and whether the fit was saved. After
+// --reset it only holds the default parameters, and Fitted is false.
+type optimizeOutput struct {
+	Scheduler        string    `json:"scheduler"`
+	Samples          int       `json:"samples"`
+	ActualRetention  float64   `json:"actual_retention"`
+	CurrentRetention float64   `json:"current_predicted_retention"`
+	FittedRetention  float64   `json:"fitted_predicted_retention"`
+	CurrentLogLoss   float64   `json:"current_log_loss"`
+	FittedLogLoss    float64   `json:"fitted_log_loss"`
+	Params           []float64 `json:"params"`
+	Fitted           bool      `json:"fitted"`
+	Saved            bool      `json:"saved"`
+}
+
+// optimizeSkipped is the output of optimize when there is nothing to fit.
+type optimizeSkipped struct {
+	Scheduler string `json:"scheduler"`
+	Fitted    bool   `json:"fitted"` // Always false
+	Reason    string `json:"reason"`
+	Samples   int    `json:"samples"`
+}
+
+func init() {
+	rootCmd.AddCommand(optimizeCmd)
+	optimizeCmd.Flags().BoolVar(&optimizeDryRun, "dry-run", false, "Show the fit without saving it")
+	optimizeCmd.Flags().BoolVar(&optimizeReset, "reset", false, "Discard fitted parameters and use the defaults")
+}
+
+// groupReviews splits a review log ordered by problem and time into one
+// history per problem.
+func groupReviews(reviews []models.Review) [][]models.Review {
+	var histories [][]models.Review
+	for i, r := range reviews {
+		if i == 0 || r.ProblemID != reviews[i-1].ProblemID {
+			histories = append(histories, nil)
+		}
+		histories[len(histories)-1] = append(histories[len(histories)-1], r)
+	}
+	return histories
+}
+
+func formatParams(params []float64) string {
+	s := ""
+	for i, v := range params {
+		if i > 0 {
+			s += ", "
+		}
+		s += fmt.Sprintf("%.4f", v)
+	}
+	return s
+}
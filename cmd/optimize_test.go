@@ -0,0 +1,38 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// Without enough history to fit, structured output still says why.
+func TestOptimizeNotFitted(t *testing.T) {
+	store := db.NewMemoryStore()
+	id, _ := store.AddProblem(models.Problem{Name: "Two Sum", Difficulty: 3})
+	store.AddReview(models.Review{ProblemID: id, Quality: 4})
+
+	for _, tt := range []struct {
+		scheduler, reason string
+	}{
+		{"sm2", "not enough review history"},
+		{"leitner", "no parameters to fit"},
+	} {
+		store.SetSetting("scheduler", tt.scheduler)
+		out := execute(t, store, "", "optimize", "--output", "json", "--dry-run", "--reset=false")
+		var got struct {
+			Scheduler string `json:"scheduler"`
+			Fitted    bool   `json:"fitted"`
+			Reason    string `json:"reason"`
+			Samples   int    `json:"samples"`
+		}
+		if err := json.Unmarshal([]byte(out), &got); err != nil {
+			t.Fatalf("%s: %v in %q", tt.scheduler, err, out)
+		}
+		if got.Scheduler != tt.scheduler || got.Fitted || got.Reason != tt.reason || got.Samples != 0 {
+			t.Errorf("%s: got %+v, want not fitted because of %s", tt.scheduler, got, tt.reason)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/LavenderBridge/spaced-repetition/internal/output"
+)
+
+var (
+	outputFlag   string
+	outputFormat = output.Table
+)
+
+// structured reports whether --output asked for machine-readable output.
+func structured() bool {
+	return outputFormat != output.Table
+}
+
+// emit prints a command's result: by calling table for --output table, or
+// by rendering data in the chosen format otherwise. Lists should be
+// non-nil so they render as [] rather than null.
+func emit(data any, table func()) error {
+	if !structured() {
+		table()
+		return nil
+	}
+	if err := output.Write(os.Stdout, outputFormat, data); err != nil {
+		return fmt.Errorf("error writing output: %w", err)
+	}
+	return nil
+}
+
+// messages is where prompts and notes go. They share stdout with tables,
+// but would corrupt structured output, so they move to stderr with it.
+func messages() io.Writer {
+	if structured() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// problemOutput is a problem as commands output it, with its tags as plain
+// names.
+type problemOutput struct {
+	models.Problem
+	Tags []string `json:"tags"`
+}
+
+func problemRow(p models.Problem) problemOutput {
+	tags := make([]string, len(p.Tags))
+	for i, t := range p.Tags {
+		tags[i] = t.Name
+	}
+	return problemOutput{Problem: p, Tags: tags}
+}
+
+func problemRows(problems []models.Problem) []problemOutput {
+	rows := make([]problemOutput, len(problems))
+	for i, p := range problems {
+		rows[i] = problemRow(p)
+	}
+	return rows
+}
+
+// tagList joins a problem's tag names for table columns.
+func tagList(p models.Problem) string {
+	return strings.Join(problemRow(p).Tags, ", ")
+}
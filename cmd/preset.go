@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/config"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	presetMaxInterval     int
+	presetEaseFloor       float64
+	presetTargetRetention float64
+	presetLearningSteps   string
+	presetRelearningSteps string
+)
+
+var presetCmd = &cobra.Command{
+	Use:   "preset",
+	Short: "Manage scheduler presets for tags",
+	Long: `Presets override scheduler settings (maximum interval, ease floor, target retention
+and learning steps) for every problem with a tag the preset is assigned to.
+When a problem has several tags with presets, the strictest one (highest target
+retention, then shortest maximum interval) applies.`,
+}
+
+var presetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List presets and the tags they are assigned to",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer store.Close()
+
+		presets, err := store.ListPresets()
+		if err != nil {
+			return fmt.Errorf("error listing presets: %w", err)
+		}
+		assignments, err := store.PresetAssignments()
+		if err != nil {
+			return fmt.Errorf("error listing preset tags: %w", err)
+		}
+		rows := make([]presetOutput, len(presets))
+		for i, p := range presets {
+			rows[i] = presetRow(p, assignments)
+		}
+
+		inherit := func(v string) string {
+			if v == "" || v == "0" {
+				return "-"
+			}
+			return v
+		}
+
+		return emit(rows, func() {
+			if len(rows) == 0 {
+				fmt.Println("No presets yet. Create one with 'recall preset set [name] --max-interval 30'.")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Preset\tMax Interval\tEase Floor\tRetention\tLearning\tRelearning\tTags")
+			fmt.Fprintln(w, "------\t------------\t----------\t---------\t--------\t----------\t----")
+			for _, p := range rows {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					p.Name,
+					inherit(fmt.Sprint(p.MaxInterval)),
+					inherit(fmt.Sprint(p.EaseFloor)),
+					inherit(fmt.Sprint(p.TargetRetention)),
+					inherit(p.LearningSteps),
+					inherit(p.RelearningSteps),
+					strings.Join(p.Tags, ", "))
+			}
+			w.Flush()
+		})
+	},
+}
+
+var presetSetCmd = &cobra.Command{
+	Use:   "set [name]",
+	Short: "Create or update a preset",
+	Long: `Create a preset, or change the given settings of an existing one.
+Settings that are not given inherit the defaults from 'recall config'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer store.Close()
+
+		presets, err := store.ListPresets()
+		if err != nil {
+			return fmt.Errorf("error listing presets: %w", err)
+		}
+		preset := models.Preset{Name: args[0]}
+		for _, p := range presets {
+			if p.Name == preset.Name {
+				preset = p
+			}
+		}
+
+		flags := cmd.Flags()
+		if flags.Changed("max-interval") {
+			if presetMaxInterval < 0 {
+				return errors.New("--max-interval cannot be negative")
+			}
+			preset.MaxInterval = presetMaxInterval
+		}
+		if flags.Changed("ease-floor") {
+			if presetEaseFloor != 0 && presetEaseFloor < 1 {
+				return errors.New("--ease-floor must be at least 1")
+			}
+			preset.EaseFloor = presetEaseFloor
+		}
+		if flags.Changed("retention") {
+			if presetTargetRetention != 0 {
+				if _, err := config.ParseRetention(fmt.Sprint(presetTargetRetention)); err != nil {
+					return err
+				}
+			}
+			preset.TargetRetention = presetTargetRetention
+		}
+		if flags.Changed("learning-steps") {
+			if presetLearningSteps != "" {
+				if _, err := config.ParseSteps(presetLearningSteps); err != nil {
+					return err
+				}
+			}
+			preset.LearningSteps = presetLearningSteps
+		}
+		if flags.Changed("relearning-steps") {
+			if presetRelearningSteps != "" {
+				if _, err := config.ParseSteps(presetRelearningSteps); err != nil {
+					return err
+				}
+			}
+			preset.RelearningSteps = presetRelearningSteps
+		}
+
+		if err := store.SavePreset(preset); err != nil {
+			return fmt.Errorf("error saving preset: %w", err)
+		}
+		assignments, err := store.PresetAssignments()
+		if err != nil {
+			return fmt.Errorf("error listing preset tags: %w", err)
+		}
+		return emit(presetRow(preset, assignments), func() { fmt.Printf("✅ Saved preset '%s'\n", preset.Name) })
+	},
+}
+
+var presetDeleteCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Short: "Delete a preset and unassign it from its tags",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer store.Close()
+
+		presets, err := store.ListPresets()
+		if err != nil {
+			return fmt.Errorf("error listing presets: %w", err)
+		}
+		assignments, err := store.PresetAssignments()
+		if err != nil {
+			return fmt.Errorf("error listing preset tags: %w", err)
+		}
+		deleted := presetRow(models.Preset{Name: args[0]}, assignments)
+		for _, p := range presets {
+			if p.Name == args[0] {
+				deleted = presetRow(p, assignments)
+			}
+		}
+
+		if err := store.DeletePreset(args[0]); err != nil {
+			return fmt.Errorf("error deleting preset: %w", err)
+		}
+		return emit(deleted, func() { fmt.Printf("✅ Deleted preset '%s'\n", args[0]) })
+	},
+}
+
+var presetAssignCmd = &cobra.Command{
+	Use:   "assign [preset] [tag...]",
+	Short: "Use a preset for problems with the given tags",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer store.Close()
+
+		presets, err := store.ListPresets()
+		if err != nil {
+			return fmt.Errorf("error listing presets: %w", err)
+		}
+		found := false
+		for _, p := range presets {
+			found = found || p.Name == args[0]
+		}
+		if !found {
+			return fmt.Errorf("no preset named '%s' (create it with 'recall preset set %s')", args[0], args[0])
+		}
+
+		assigned := make([]tagPreset, 0, len(args)-1)
+		for _, tag := range args[1:] {
+			if err := store.AssignPreset(tag, args[0]); err != nil {
+				return fmt.Errorf("error assigning preset: %w", err)
+			}
+			assigned = append(assigned, tagPreset{tag, args[0]})
+		}
+		return emit(assigned, func() {
+			fmt.Printf("✅ Tags %s now use preset '%s'\n", strings.Join(args[1:], ", "), args[0])
+		})
+	},
+}
+
+var presetUnassignCmd = &cobra.Command{
+	Use:   "unassign [tag...]",
+	Short: "Go back to the default settings for the given tags",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer store.Close()
+
+		unassigned := make([]tagPreset, 0, len(args))
+		for _, tag := range args {
+			if err := store.AssignPreset(tag, ""); err != nil {
+				return fmt.Errorf("error unassigning preset: %w", err)
+			}
+			unassigned = append(unassigned, tagPreset{Tag: tag})
+		}
+		return emit(unassigned, func() {
+			fmt.Printf("✅ Tags %s now use the default settings\n", strings.Join(args, ", "))
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(presetCmd)
+	presetCmd.AddCommand(presetListCmd, presetSetCmd, presetDeleteCmd, presetAssignCmd, presetUnassignCmd)
+
+	presetSetCmd.Flags().IntVar(&presetMaxInterval, "max-interval", 0, "Longest interval in days (0 to inherit)")
+	presetSetCmd.Flags().Float64Var(&presetEaseFloor, "ease-floor", 0, "Lowest SM-2 ease (0 to inherit)")
+	presetSetCmd.Flags().Float64Var(&presetTargetRetention, "retention", 0, "Target recall probability, e.g. 0.95 (0 to inherit)")
+	presetSetCmd.Flags().StringVar(&presetLearningSteps, "learning-steps", "", "Learning steps, e.g. 5m,30m or none (empty to inherit)")
+	presetSetCmd.Flags().StringVar(&presetRelearningSteps, "relearning-steps", "", "Relearning steps, e.g. 10m or none (empty to inherit)")
+}
+
+// presetOutput is a preset as the preset commands output it, with the
+// tags it is assigned to.
+type presetOutput struct {
+	models.Preset
+	Tags []string `json:"tags"`
+}
+
+// presetRow pairs a preset with its tags, given the preset of each tag.
+func presetRow(p models.Preset, assignments map[string]string) presetOutput {
+	tags := []string{}
+	for tag, preset := range assignments {
+		if preset == p.Name {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return presetOutput{Preset: p, Tags: tags}
+}
+
+// tagPreset is a tag and the preset it uses, "" for the defaults.
+type tagPreset struct {
+	Tag    string `json:"tag"`
+	Preset string `json:"preset"`
+}
+
+// presetResolver returns a function naming the preset that applies to a
+// problem. When several of its tags have presets, the strictest wins:
+// highest target retention, then shortest maximum interval, then name.
+func presetResolver(presets []models.Preset, cfg config.Config) func(models.Problem) string {
+	ranked := append([]models.Preset(nil), presets...)
+	retention := func(p models.Preset) float64 {
+		if p.TargetRetention > 0 {
+			return p.TargetRetention
+		}
+		return cfg.TargetRetention
+	}
+	maxInterval := func(p models.Preset) int {
+		if p.MaxInterval > 0 {
+			return p.MaxInterval
+		}
+		return int(^uint(0) >> 1)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if retention(ranked[i]) != retention(ranked[j]) {
+			return retention(ranked[i]) > retention(ranked[j])
+		}
+		if maxInterval(ranked[i]) != maxInterval(ranked[j]) {
+			return maxInterval(ranked[i]) < maxInterval(ranked[j])
+		}
+		return ranked[i].Name < ranked[j].Name
+	})
+	rank := make(map[string]int, len(ranked))
+	for i, p := range ranked {
+		rank[p.Name] = i
+	}
+
+	return func(p models.Problem) string {
+		best := ""
+		for _, t := range p.Tags {
+			r, ok := rank[t.Preset]
+			if ok && (best == "" || r < rank[best]) {
+				best = t.Preset
+			}
+		}
+		return best
+	}
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var rescheduleDryRun bool
+
+var rescheduleCmd = &cobra.Command{
+	Use:   "reschedule",
+	Short: "Recompute every schedule from review history",
+	Long: `Replay each problem's review history through the current scheduler and
+parameters, and rewrite its interval, ease and next review date.
+Use this after switching schedulers or running 'recall optimize'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer store.Close()
+
+		sched, err := loadScheduler(store)
+		if err != nil {
+			return fmt.Errorf("error loading scheduler: %w", err)
+		}
+
+		problems, err := store.ListProblems(false)
+		if err != nil {
+			return fmt.Errorf("error fetching problems: %w", err)
+		}
+		reviews, err := store.ListReviews()
+		if err != nil {
+			return fmt.Errorf("error fetching reviews: %w", err)
+		}
+		histories := make(map[int][]models.Review)
+		for _, h := range groupReviews(reviews) {
+			histories[h[0].ProblemID] = h
+		}
+
+		cal := store.Calendar()
+		var changed []models.Problem
+		changes := []scheduleChange{}
+		for _, p := range problems {
+			history, ok := histories[p.ID]
+			if !ok {
+				continue
+			}
+			updated := replayProblem(sched, p, history)
+			if sameSchedule(p, updated) {
+				continue
+			}
+			changed = append(changed, updated)
+			changes = append(changes, scheduleChange{p.ID, p.Name, p.NextReview, updated.NextReview, p.Interval, updated.Interval})
+		}
+
+		if len(changed) > 0 && !rescheduleDryRun {
+			if err := store.RescheduleProblems(changed); err != nil {
+				return fmt.Errorf("error rescheduling problems: %w", err)
+			}
+		}
+
+		return emit(changes, func() {
+			if len(changes) == 0 {
+				fmt.Println("✅ All schedules are already up to date.")
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tProblem\tOld Due\tNew Due\tInterval")
+			fmt.Fprintln(w, "--\t-------\t-------\t-------\t--------")
+			for _, c := range changes {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%dd -> %dd\n",
+					c.ID, c.Name, cal.Date(c.OldNextReview), cal.Date(c.NewNextReview), c.OldInterval, c.NewInterval)
+			}
+			w.Flush()
+			fmt.Println()
+
+			if rescheduleDryRun {
+				fmt.Printf("ℹ️ Dry run, %d problems would be rescheduled with %s.\n", len(changes), sched.Name())
+			} else {
+				fmt.Printf("✅ Rescheduled %d problems with %s.\n", len(changes), sched.Name())
+			}
+		})
+	},
+}
+
+// scheduleChange is a problem whose schedule reschedule changes.
+type scheduleChange struct {
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	OldNextReview time.Time `json:"old_next_review"`
+	NewNextReview time.Time `json:"new_next_review"`
+	OldInterval   int       `json:"old_interval"`
+	NewInterval   int       `json:"new_interval"`
+}
+
+func init() {
+	rootCmd.AddCommand(rescheduleCmd)
+	rescheduleCmd.Flags().BoolVar(&rescheduleDryRun, "dry-run", false, "Show the changes without saving them")
+}
+
+// replayProblem recomputes p's schedule from its review history. Synthetic
+// reviews imported from legacy data carry no real grade, so a history that
+// starts with one resumes from its interval and ease snapshot instead.
+func replayProblem(sched algorithm.Scheduler, p models.Problem, history []models.Review) models.Problem {
+	if history[0].Notes != db.LegacyReviewNote {
+		return algorithm.Replay(sched, p, history)
+	}
+
+	legacy := history[0]
+	p.Interval = legacy.Interval
+	p.EaseFactor = legacy.EaseFactor
+	p.Stability = 0
+	p.FSRSDifficulty = 0
+	p.State = models.StateReview
+	p.Step = 0
+	p.Lapses = 0
+	p.Box = 0
+	p.LastReviewed = legacy.ReviewedAt
+	p.NextReview = legacy.ReviewedAt.AddDate(0, 0, legacy.Interval)
+	for _, r := range history[1:] {
+		p = sched.Review(p, r.Quality, r.ReviewedAt)
+	}
+	return p
+}
+
+func sameSchedule(a, b models.Problem) bool {
+	return a.Interval == b.Interval &&
+		a.EaseFactor == b.EaseFactor &&
+		a.Stability == b.Stability &&
+		a.FSRSDifficulty == b.FSRSDifficulty &&
+		a.State == b.State &&
+		a.Lapses == b.Lapses &&
+		a.Box == b.Box &&
+		a.NextReview.Equal(b.NextReview)
+}
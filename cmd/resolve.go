@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// maxChoices is how many matches an ambiguous query offers to pick from.
+const maxChoices = 10
+
+var errCancelled = errors.New("cancelled")
+
+// resolveProblem finds the problem a command argument refers to: an ID, a
+// URL slug such as two-sum, an exact name or part of one. When several
+// problems match it asks which one was meant, reading the answer from in.
+func resolveProblem(store db.Repository, query string, in *bufio.Reader) (*models.Problem, error) {
+	matches, err := store.FindProblems(query)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(matches) == 0:
+		return nil, fmt.Errorf("no problem matches %q", query)
+	case len(matches) == 1:
+		return &matches[0], nil
+	case len(matches) > maxChoices:
+		return nil, fmt.Errorf("%d problems match %q, be more specific", len(matches), query)
+	}
+
+	out := messages()
+	fmt.Fprintf(out, "🔎 %d problems match %q:\n", len(matches), query)
+	for i, p := range matches {
+		fmt.Fprintf(out, "  %d) %s (ID %d)\n", i+1, p.Name, p.ID)
+	}
+	fmt.Fprintf(out, "Which one? (1-%d, Enter to cancel): ", len(matches))
+	input, _ := in.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, errCancelled
+	}
+	n, err := strconv.Atoi(input)
+	if err != nil || n < 1 || n > len(matches) {
+		return nil, fmt.Errorf("invalid choice %q", input)
+	}
+	return &matches[n-1], nil
+}
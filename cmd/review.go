@@ -2,65 +2,110 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
-	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/config"
 	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/LavenderBridge/spaced-repetition/internal/query"
 	"github.com/spf13/cobra"
 	"os/exec"
 	"runtime"
 )
 
-var reviewOpen bool
+var (
+	reviewOpen   bool
+	reviewByRisk bool
+)
 
 var reviewCmd = &cobra.Command{
-	Use:   "review [optional problem name]",
+	Use:   "review [optional problem]",
 	Short: "Start a review session",
 	Long: `Start a review session. 
-If a problem name is provided, review that specific problem.
-If no name provided, review all problems due today.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		store, err := db.NewStore()
+If a problem is given (by ID, URL slug, exact name or part of its name),
+review that specific problem.
+If no problem is given, review all problems due today.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if structured() {
+			return errors.New("review is interactive and only supports --output table")
+		}
+
+		store, err := openStore(cmd.Context())
 		if err != nil {
-			fmt.Println("❌ Database error:", err)
-			return
+			return fmt.Errorf("database error: %w", err)
 		}
 		defer store.Close()
 
+		sched, err := loadScheduler(store)
+		if err == nil {
+			sched, err = withFuzz(store, sched)
+		}
+		if err != nil {
+			return fmt.Errorf("error loading scheduler: %w", err)
+		}
+
+		cfg, err := config.Load(store.GetSetting)
+		if err != nil {
+			return fmt.Errorf("error loading settings: %w", err)
+		}
+
 		var problems []models.Problem
+		reader := bufio.NewReader(os.Stdin)
 
 		if len(args) > 0 {
-			// Review specific problem by exact name match
-			name := strings.Join(args, " ")
-			p, err := store.GetProblem(name)
+			// Review a specific problem
+			p, err := resolveProblem(store, strings.Join(args, " "), reader)
+			if errors.Is(err, errCancelled) {
+				fmt.Println("❌ Cancelled.")
+				return nil
+			}
 			if err != nil {
-				fmt.Println("❌ Problem not found or error:", err)
-				return
+				return err
 			}
 			problems = append(problems, *p)
 		} else {
 			// Review due problems
 			problems, err = store.ListProblems(true) // dueOnly = true
 			if err != nil {
-				fmt.Println("❌ Error fetching due problems:", err)
-				return
+				return fmt.Errorf("error fetching due problems: %w", err)
 			}
 			if len(problems) == 0 {
 				fmt.Println("✅ No problems due for review today!")
-				return
+				return nil
+			}
+			if reviewByRisk {
+				// Most likely forgotten first
+				predictRecall(sched, problems)
+				query.Sort(problems, query.SortRecall, false)
 			}
 		}
 
-		reader := bufio.NewReader(os.Stdin)
+		cal := store.Calendar()
+
+		// Problems that enter a learning step are queued again, so the
+		// session cycles back to them once their step is due.
+		queue := problems
+		reviewed, unsaved := 0, 0
+		for len(queue) > 0 {
+			i := nextInQueue(queue, appClock.Now())
+			p := queue[i]
+			queue = append(queue[:i:i], queue[i+1:]...)
 
-		for i, p := range problems {
+			if wait := p.NextReview.Sub(appClock.Now()); p.State.InLearning() && wait > 0 {
+				fmt.Printf("\n⏳ '%s' is due again in %s. Press Enter to review it now, or q to stop: ", p.Name, formatWait(wait))
+				input, _ := reader.ReadString('\n')
+				if strings.TrimSpace(strings.ToLower(input)) == "q" {
+					break
+				}
+			}
+
+			reviewed++
 			fmt.Println("\n========================================")
-			fmt.Printf("Reviewing [%d/%d]: %s\n", i+1, len(problems), p.Name)
+			fmt.Printf("Reviewing [%d/%d]: %s\n", reviewed, reviewed+len(queue), p.Name)
 			if p.URL != "" {
 				fmt.Printf("URL: %s\n", p.URL)
 			}
@@ -72,8 +117,7 @@ If no name provided, review all problems due today.`,
 			// Show last review info
 			lastReview, _ := store.GetLastReview(p.ID)
 			if lastReview != nil {
-				since := time.Since(lastReview.ReviewedAt)
-				days := int(since.Hours() / 24)
+				days := cal.DaysBetween(lastReview.ReviewedAt, appClock.Now())
 				var timeStr string
 				if days == 0 {
 					timeStr = "Today"
@@ -82,11 +126,11 @@ If no name provided, review all problems due today.`,
 				} else {
 					timeStr = fmt.Sprintf("%dD ago", days)
 				}
-				fmt.Printf("Last reviewed: %s (%s) - Quality: %d\n", lastReview.ReviewedAt.Format("2006-01-02"), timeStr, lastReview.Quality)
+				fmt.Printf("Last reviewed: %s (%s) - Quality: %d\n", cal.Date(lastReview.ReviewedAt), timeStr, lastReview.Quality)
 			} else {
 				fmt.Println("Last reviewed: Never")
 			}
-			
+
 			if reviewOpen && p.URL != "" {
 				fmt.Println("🌐 Opening URL in browser...")
 				openBrowser(p.URL)
@@ -98,7 +142,7 @@ If no name provided, review all problems due today.`,
 			fmt.Print("Rate recall quality (0: Blackout -> 5: Perfect): ")
 			input, _ := reader.ReadString('\n')
 			input = strings.TrimSpace(input)
-			
+
 			quality, err := strconv.Atoi(input)
 			if err != nil || quality < 0 || quality > 5 {
 				fmt.Println("⚠️ Invalid input, skipping update for this problem.")
@@ -109,34 +153,101 @@ If no name provided, review all problems due today.`,
 			note, _ := reader.ReadString('\n')
 			note = strings.TrimSpace(note)
 
-			// Update
-			updated := algorithm.CalculateReview(p, quality)
-			if err := store.UpdateProblem(updated); err != nil {
-				fmt.Printf("❌ Error updating problem: %v\n", err)
+			// Update the schedule and history together
+			now := appClock.Now()
+			updated := sched.Review(p, quality, now)
+			review := models.Review{
+				ProblemID:      p.ID,
+				Quality:        quality,
+				ReviewedAt:     now,
+				Notes:          note,
+				Interval:       updated.Interval,
+				EaseFactor:     updated.EaseFactor,
+				Stability:      updated.Stability,
+				FSRSDifficulty: updated.FSRSDifficulty,
+				// What was planned versus what happened, e.g. for overdue reviews
+				ScheduledInterval: p.Interval,
+				ActualInterval:    cal.DaysBetween(p.LastReviewed, now),
+			}
+			// Only the lapse that crosses the threshold makes a leech, so an
+			// unsuspended leech is not suspended again on its next lapse.
+			leech := !cfg.IsLeech(p.Lapses) && cfg.IsLeech(updated.Lapses)
+			if leech {
+				markLeech(cfg, &updated)
+			}
+			if err := store.RecordReview(updated, review); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Error saving review: %v\n", err)
+				unsaved++
 			} else {
-				// Save review history
-				review := models.Review{
-					ProblemID:  p.ID,
-					Quality:    quality,
-					ReviewedAt: time.Now(),
-					Notes:      note,
-					Interval:   updated.Interval,
-					EaseFactor: updated.EaseFactor,
+				if leech {
+					fmt.Printf("🩸 '%s' has lapsed %d times and is now a leech. Consider re-studying the technique behind it.\n", updated.Name, updated.Lapses)
 				}
-				if err := store.AddReview(review); err != nil {
-					fmt.Printf("⚠️ Failed to save review history: %v\n", err)
+				if updated.Suspended {
+					fmt.Println("⏸️  Suspended until you re-study it (recall edit --unsuspend).")
+				} else if updated.State.InLearning() {
+					fmt.Printf("🔁 Learning step: again in %s.\n", formatWait(updated.NextReview.Sub(now)))
+					if updated.NextReview.Before(cal.DayEnd(now)) {
+						queue = append(queue, updated)
+					}
+				} else {
+					fmt.Printf("✅ Updated! Next review in %d days.\n", updated.Interval)
 				}
-				fmt.Printf("✅ Updated! Next review in %d days.\n", updated.Interval)
 			}
 		}
 
+		if unsaved > 0 {
+			return fmt.Errorf("%d of the reviews could not be saved", unsaved)
+		}
 		fmt.Println("\n🎉 Review session complete!")
+		return nil
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(reviewCmd)
 	reviewCmd.Flags().BoolVarP(&reviewOpen, "open", "o", false, "Open problem URL in browser")
+	reviewCmd.Flags().BoolVar(&reviewByRisk, "by-risk", false, "Review the problems you are most likely to have forgotten first")
+}
+
+// markLeech tags a problem that keeps lapsing, and suspends it if the
+// configured leech action asks for it. RecordReview saves both with the
+// review.
+func markLeech(cfg config.Config, p *models.Problem) {
+	if !p.HasTag(models.LeechTag) {
+		p.Tags = append(p.Tags, models.Tag{Name: models.LeechTag})
+	}
+	if cfg.LeechAction == "suspend" {
+		p.Suspended = true
+	}
+}
+
+// nextInQueue picks the first problem that is ready now, or else the
+// learning step that comes due soonest.
+func nextInQueue(queue []models.Problem, now time.Time) int {
+	next := 0
+	for i, p := range queue {
+		if !p.State.InLearning() || !p.NextReview.After(now) {
+			return i
+		}
+		if p.NextReview.Before(queue[next].NextReview) {
+			next = i
+		}
+	}
+	return next
+}
+
+func formatWait(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "under a minute"
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
 }
 
 func openBrowser(url string) {
@@ -152,6 +263,6 @@ func openBrowser(url string) {
 		err = fmt.Errorf("unsupported platform")
 	}
 	if err != nil {
-		fmt.Printf("❌ Failed to open browser: %v\n", err)
+		fmt.Fprintf(os.Stderr, "❌ Failed to open browser: %v\n", err)
 	}
 }
@@ -0,0 +1,37 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+func TestReviewLeech(t *testing.T) {
+	store := db.NewMemoryStore()
+	store.SetSetting("leech_threshold", "2")
+	store.SetSetting("leech_action", "suspend")
+	last := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store.AddProblem(models.Problem{Name: "Two Sum", Difficulty: 3, Interval: 10, EaseFactor: 2.5,
+		LastReviewed: last, NextReview: last.AddDate(0, 0, 10), State: models.StateReview, Lapses: 1})
+
+	// Forgetting it a second time makes it a leech.
+	out := execute(t, store, "\n0\n\n", "--now", "2026-03-11 12:00", "review", "Two Sum", "--output", "table")
+	p, _ := store.GetProblem("Two Sum")
+	if !strings.Contains(out, "is now a leech") || !p.Suspended || !p.HasTag(models.LeechTag) {
+		t.Fatalf("second lapse: got suspended %v and tags %v, want a suspended leech; output:\n%s", p.Suspended, p.Tags, out)
+	}
+
+	// Once unsuspended after re-studying it, further lapses leave it be.
+	store.SetSuspended(p.ID, false)
+	out = execute(t, store, "\n0\n\n", "--now", "2026-03-12 12:00", "review", "Two Sum", "--output", "table")
+	p, _ = store.GetProblem("Two Sum")
+	if strings.Contains(out, "is now a leech") || p.Suspended || p.Lapses != 3 {
+		t.Errorf("lapse after unsuspending: got suspended %v and %d lapses, want unsuspended and 3; output:\n%s", p.Suspended, p.Lapses, out)
+	}
+	if len(p.Tags) != 1 {
+		t.Errorf("lapse after unsuspending: got tags %v, want only leech", p.Tags)
+	}
+}
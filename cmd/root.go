@@ -1,25 +1,193 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+	"github.com/LavenderBridge/spaced-repetition/internal/config"
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/output"
 	"github.com/spf13/cobra"
 )
 
+var (
+	// appClock is the single source of "now" for every command.
+	appClock = clock.System()
+	nowFlag  string
+
+	dbFlag         string
+	collectionFlag string
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "recall",
 	Short: "A spaced repetition tool for LeetCode practice",
 	Long: `Recall is a CLI tool to help you practice LeetCode problems
-using a spaced repetition algorithm (SM-2).`,
-	Run: func(cmd *cobra.Command, args []string) {
-		cmd.Help()
+using a spaced repetition algorithm (SM-2, FSRS or Leitner boxes).`,
+	// Execute prints errors itself, to stderr.
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		format, err := output.ParseFormat(outputFlag)
+		if err != nil {
+			return err
+		}
+		outputFormat = format
+		// The command line is valid from here on, so errors no longer
+		// come with the usage.
+		cmd.SilenceUsage = true
+		if nowFlag == "" {
+			return nil
+		}
+		// Check the syntax now; openStore reads it again in the
+		// configured timezone.
+		now, err := clock.Parse(nowFlag, clock.Calendar{})
+		if err != nil {
+			return err
+		}
+		appClock = clock.Fixed(now)
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
 	},
 }
 
+// Execute runs the command line against the SQLite database chosen by the
+// flags, environment and config file.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+	execute(openSQLite)
+}
+
+// ExecuteWith runs the command line against the repositories returned by
+// open for each database path instead of SQLite databases.
+func ExecuteWith(open func(path string) (db.Repository, error)) {
+	execute(open)
+}
+
+// openerKey is the context key of the function commands open repositories
+// with.
+type openerKey struct{}
+
+// execute runs the command line with open in the context of every command.
+func execute(open func(path string) (db.Repository, error)) {
+	ctx := context.WithValue(context.Background(), openerKey{}, open)
+	// Cobra only hands the context down to commands that have none yet, so
+	// set it on every command for each run.
+	var withContext func(c *cobra.Command)
+	withContext = func(c *cobra.Command) {
+		c.SetContext(ctx)
+		for _, sub := range c.Commands() {
+			withContext(sub)
+		}
+	}
+	withContext(rootCmd)
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
 		os.Exit(1)
 	}
 }
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&nowFlag, "now", "", "Pretend the current time is this (YYYY-MM-DD [HH:MM])")
+	rootCmd.PersistentFlags().MarkHidden("now")
+	rootCmd.PersistentFlags().StringVar(&dbFlag, "db", "", "Database file to use (overrides $RECALL_DB and the config file)")
+	rootCmd.PersistentFlags().StringVarP(&collectionFlag, "collection", "c", "", "Named collection to use, e.g. work")
+	rootCmd.MarkFlagsMutuallyExclusive("db", "collection")
+	rootCmd.PersistentFlags().StringVar(&outputFlag, "output", string(output.Table), "Output format: table, json, jsonl, csv or yaml")
+}
+
+// databasePath decides which database to open: the --db or --collection
+// flag, then $RECALL_DB, then the db or collection set in ~/.recall/config,
+// and finally the default collection.
+func databasePath() (string, error) {
+	if dbFlag != "" {
+		return dbFlag, nil
+	}
+	if collectionFlag != "" {
+		return existingCollection(collectionFlag)
+	}
+	if env := os.Getenv("RECALL_DB"); env != "" {
+		return env, nil
+	}
+
+	path, err := config.FilePath()
+	if err != nil {
+		return "", err
+	}
+	file, err := config.LoadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if file.DB != "" {
+		return file.DB, nil
+	}
+	if file.Collection != "" {
+		return existingCollection(file.Collection)
+	}
+	return db.CollectionPath(db.DefaultCollection)
+}
+
+// existingCollection returns the database path of a collection, which
+// must have been created first unless it is the default one.
+func existingCollection(name string) (string, error) {
+	if name != db.DefaultCollection {
+		exists, err := db.CollectionExists(name)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return "", fmt.Errorf("no collection named %q (create it with 'recall collections create %s')", name, name)
+		}
+	}
+	return db.CollectionPath(name)
+}
+
+// openSQLite opens the SQLite database at a database path.
+func openSQLite(path string) (db.Repository, error) {
+	store, err := db.NewStore(path)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// openRepository opens the storage at a database path with the opener the
+// command line was run with, SQLite unless ExecuteWith was given another.
+func openRepository(ctx context.Context, path string) (db.Repository, error) {
+	open, ok := ctx.Value(openerKey{}).(func(path string) (db.Repository, error))
+	if !ok {
+		open = openSQLite
+	}
+	return open(path)
+}
+
+// openStore opens the repository and wires it to the application clock and
+// the study-day calendar configured for it, reading --now in that calendar.
+func openStore(ctx context.Context) (db.Repository, error) {
+	path, err := databasePath()
+	if err != nil {
+		return nil, err
+	}
+	store, err := openRepository(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load(store.GetSetting)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	if nowFlag != "" {
+		now, err := clock.Parse(nowFlag, cfg.Calendar())
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		appClock = clock.Fixed(now)
+	}
+	store.SetClock(appClock)
+	store.SetCalendar(cfg.Calendar())
+	return store, nil
+}
@@ -0,0 +1,101 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LavenderBridge/spaced-repetition/cmd"
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+)
+
+func TestExecuteWith(t *testing.T) {
+	store := db.NewMemoryStore()
+	var opened []string
+	open := func(path string) (db.Repository, error) {
+		opened = append(opened, path)
+		return store, nil
+	}
+	path := filepath.Join(t.TempDir(), "recall.db")
+	args := os.Args
+	defer func() { os.Args = args }()
+	run := func(args ...string) {
+		os.Args = append([]string{"recall", "--db", path}, args...)
+		cmd.ExecuteWith(open)
+	}
+
+	run("add", "Two Sum", "3", "--tags", "array,hash")
+	run("edit", "two-sum", "--difficulty", "4")
+
+	p, err := store.GetProblem("Two Sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Difficulty != 4 || len(p.Tags) != 2 {
+		t.Errorf("got difficulty %d and tags %v, want 4 and [array hash]", p.Difficulty, p.Tags)
+	}
+	if len(opened) != 2 || opened[0] != path {
+		t.Errorf("opened %v, want %s twice", opened, path)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("%s was created, want only the memory store used", path)
+	}
+
+	// Each run opens repositories with its own opener.
+	other := db.NewMemoryStore()
+	execute(t, other, "", "add", "Coin Change", "4", "--tags", "", "--output", "table")
+	if _, err := other.GetProblem("Coin Change"); err != nil {
+		t.Errorf("second run: %v", err)
+	}
+	if _, err := store.GetProblem("Coin Change"); err == nil {
+		t.Error("second run: added to the first run's store")
+	}
+
+	// A name that is also an ID must not get the output mixed up with
+	// problem 1.
+	out := execute(t, store, "", "add", "1", "2", "--output", "json")
+	var added struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(out), &added); err != nil {
+		t.Fatalf("add --output json: %v in %q", err, out)
+	}
+	if added.ID != 2 || added.Name != "1" {
+		t.Errorf("add --output json: got problem %d %q, want 2 \"1\"", added.ID, added.Name)
+	}
+}
+
+// execute runs recall with args against store, feeding it stdin, and
+// returns what it wrote to stdout.
+func execute(t *testing.T, store db.Repository, stdin string, args ...string) string {
+	t.Helper()
+	inR, inW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		io.WriteString(inW, stdin)
+		inW.Close()
+	}()
+	out := make(chan []byte)
+	go func() {
+		b, _ := io.ReadAll(outR)
+		out <- b
+	}()
+
+	osArgs, stdinFile, stdoutFile := os.Args, os.Stdin, os.Stdout
+	os.Args = append([]string{"recall", "--db", filepath.Join(t.TempDir(), "recall.db")}, args...)
+	os.Stdin, os.Stdout = inR, outW
+	cmd.ExecuteWith(func(string) (db.Repository, error) { return store, nil })
+	os.Args, os.Stdin, os.Stdout = osArgs, stdinFile, stdoutFile
+	outW.Close()
+	inR.Close()
+	return string(<-out)
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/config"
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// schedulerSetting is the settings key holding the active scheduler name.
+const schedulerSetting = "scheduler"
+
+// paramsSetting returns the settings key holding fitted parameters for a scheduler.
+func paramsSetting(name string) string {
+	return "params." + name
+}
+
+var schedulerCmd = &cobra.Command{
+	Use:   "scheduler [sm2|fsrs|leitner]",
+	Short: "Show or change the scheduling algorithm",
+	Long: `Show the scheduling algorithm used by this database, or switch to another one:
+sm2 (SuperMemo-2), fsrs (Free Spaced Repetition Scheduler) or leitner (Leitner boxes).
+Existing problems keep their schedule; the new algorithm takes over from their next review.
+Switching to leitner places each problem in the box matching its current interval.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer store.Close()
+
+		if len(args) == 0 {
+			sched, err := loadScheduler(store)
+			if err != nil {
+				return fmt.Errorf("error loading scheduler: %w", err)
+			}
+			out := schedulerOutput{Scheduler: sched.Name(), Available: algorithm.Names()}
+			return emit(out, func() {
+				fmt.Printf("Active scheduler: %s (available: %s)\n", out.Scheduler, strings.Join(out.Available, ", "))
+			})
+		}
+
+		name := strings.ToLower(args[0])
+		if _, err := algorithm.New(name); err != nil {
+			return err
+		}
+		if err := store.SetSetting(schedulerSetting, name); err != nil {
+			return fmt.Errorf("error saving scheduler: %w", err)
+		}
+
+		out := schedulerOutput{Scheduler: name, Available: algorithm.Names()}
+		if name == algorithm.LeitnerName {
+			placed, err := placeInBoxes(store)
+			if err != nil {
+				return fmt.Errorf("error placing problems in boxes: %w", err)
+			}
+			out.Placed = placed
+		}
+		return emit(out, func() {
+			fmt.Printf("✅ Scheduler set to %s\n", name)
+			if out.Placed > 0 {
+				fmt.Printf("📦 Placed %d problems into Leitner boxes based on their current intervals.\n", out.Placed)
+			}
+		})
+	},
+}
+
+// schedulerOutput is the output of scheduler. Placed counts the problems
+// moved into Leitner boxes when switching to leitner.
+type schedulerOutput struct {
+	Scheduler string   `json:"scheduler"`
+	Available []string `json:"available"`
+	Placed    int      `json:"placed,omitempty"`
+}
+
+func init() {
+	rootCmd.AddCommand(schedulerCmd)
+}
+
+// loadScheduler returns the scheduler configured for the store's database,
+// using any parameters previously fitted by 'recall optimize' and the
+// configured options, overridden per problem by the presets of its tags.
+func loadScheduler(store db.Repository) (algorithm.Scheduler, error) {
+	name, err := store.GetSetting(schedulerSetting)
+	if err != nil {
+		return nil, err
+	}
+	sched, err := algorithm.New(name)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := store.GetSetting(paramsSetting(sched.Name()))
+	if err != nil {
+		return nil, err
+	}
+	if raw != "" {
+		var params []float64
+		if err := json.Unmarshal([]byte(raw), &params); err != nil {
+			return nil, fmt.Errorf("invalid %s parameters: %w", sched.Name(), err)
+		}
+		if sched, err = sched.WithParams(params); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, err := config.Load(store.GetSetting)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := cfg.SchedulerOptions(nil)
+	if err != nil {
+		return nil, err
+	}
+	configured := algorithm.Configure(sched, opts)
+
+	presets, err := store.ListPresets()
+	if err != nil || len(presets) == 0 {
+		return configured, err
+	}
+	resolved := algorithm.Presets{
+		Default:  configured,
+		ByName:   make(map[string]algorithm.Scheduler, len(presets)),
+		PresetOf: presetResolver(presets, cfg),
+	}
+	for _, preset := range presets {
+		opts, err := cfg.SchedulerOptions(&preset)
+		if err != nil {
+			return nil, err
+		}
+		resolved.ByName[preset.Name] = algorithm.Configure(sched, opts)
+	}
+	return resolved, nil
+}
+
+// withFuzz adds interval fuzz and load balancing to sched when enabled.
+// Replays of past reviews should use the unfuzzed scheduler so they stay
+// deterministic.
+func withFuzz(store db.Repository, sched algorithm.Scheduler) (algorithm.Scheduler, error) {
+	cfg, err := config.Load(store.GetSetting)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.IntervalFuzz {
+		return sched, nil
+	}
+
+	var load algorithm.DueLoad
+	if cfg.LoadBalance {
+		load = func(t time.Time) int {
+			count, err := store.CountDueOn(t)
+			if err != nil {
+				return 0
+			}
+			return count
+		}
+	}
+	rng := rand.New(rand.NewSource(appClock.Now().UnixNano()))
+	return algorithm.WithFuzz(sched, rng, load), nil
+}
+
+// placeInBoxes migrates problems scheduled by another algorithm into the
+// Leitner box matching their current interval, keeping their due dates.
+func placeInBoxes(store db.Repository) (int, error) {
+	cfg, err := config.Load(store.GetSetting)
+	if err != nil {
+		return 0, err
+	}
+	leitner := algorithm.Leitner{Intervals: cfg.LeitnerIntervals}
+
+	problems, err := store.ListProblems(false)
+	if err != nil {
+		return 0, err
+	}
+	var placed []models.Problem
+	for _, p := range problems {
+		if p.Box == 0 && !p.State.InLearning() {
+			placed = append(placed, leitner.Seed(p))
+		}
+	}
+	return len(placed), store.RescheduleProblems(placed)
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var searchLimit int
+
+var searchCmd = &cobra.Command{
+	Use:   "search [words...]",
+	Short: "Search problem names, notes, tags and review notes",
+	Long: `Search problem names, notes, tags and the notes written during reviews.
+Problems must contain every word, or a word starting with it; matches in the
+name rank highest, then notes, tags and review notes.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		defer store.Close()
+
+		query := strings.Join(args, " ")
+		results, err := store.Search(query, searchLimit)
+		if err != nil {
+			return fmt.Errorf("error searching: %w", err)
+		}
+		return emit(searchRows(results), func() {
+			if len(results) == 0 {
+				fmt.Printf("🔎 Nothing matches %q.\n", query)
+				return
+			}
+
+			noun := "results"
+			if len(results) == 1 {
+				noun = "result"
+			}
+			fmt.Printf("🔎 %d %s for %q:\n", len(results), noun, query)
+			start, end := "*", "*"
+			if isTerminal(os.Stdout) {
+				start, end = "\033[1m", "\033[0m"
+			}
+			for _, r := range results {
+				snippet := strings.NewReplacer(db.HighlightStart, start, db.HighlightEnd, end, "\n", " ").Replace(r.Snippet)
+				fmt.Printf("\n%d  %s\n    %s\n", r.Problem.ID, r.Problem.Name, snippet)
+			}
+		})
+	},
+}
+
+// searchOutput is a search result as search outputs it: the problem with
+// its snippet, without highlight markers, and rank.
+type searchOutput struct {
+	problemOutput
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+func searchRows(results []models.SearchResult) []searchOutput {
+	rows := make([]searchOutput, len(results))
+	unmark := strings.NewReplacer(db.HighlightStart, "", db.HighlightEnd, "")
+	for i, r := range results {
+		rows[i] = searchOutput{problemRow(r.Problem), unmark.Replace(r.Snippet), r.Rank}
+	}
+	return rows
+}
+
+// isTerminal reports whether f is an interactive terminal rather than a
+// pipe or file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "Maximum number of results (0 for all)")
+}
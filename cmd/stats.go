@@ -2,57 +2,122 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
 
-	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/LavenderBridge/spaced-repetition/internal/query"
 	"github.com/spf13/cobra"
 )
 
 var statsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "Show tracked problem statistics",
-	Run: func(cmd *cobra.Command, args []string) {
-		store, err := db.NewStore()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore(cmd.Context())
 		if err != nil {
-			fmt.Println("❌ Database error:", err)
-			return
+			return fmt.Errorf("database error: %w", err)
 		}
 		defer store.Close()
 
 		problems, err := store.ListProblems(false)
 		if err != nil {
-			fmt.Println("❌ Error fetching problems:", err)
-			return
+			return fmt.Errorf("error fetching problems: %w", err)
 		}
 
-		total := len(problems)
-		due := 0
-		mastered := 0 // Arbitrary definition: interval > 30 days
-		learning := 0 // interval < 7
-
+		stats := problemStats{Total: len(problems)}
+		now, cal := appClock.Now(), store.Calendar()
 		for _, p := range problems {
-			// Check if due
-			// This is rough approximation as ListProblems(false) doesn't filter by date
-			// But we can check NextReview vs Now if we wanted, but ListProblems doesn't give us easy Compare without parsing.
-			// Actually proper check requires comparing dates.
-			// Let's just trust 'due' command for due count, here stick to distribution.
-			
+			if query.IsDue(p, now, cal) {
+				stats.Due++
+			}
+
 			if p.Interval > 30 {
-				mastered++
+				stats.Mastered++
 			} else if p.Interval < 7 {
-				learning++
+				stats.Learning++
 			}
 		}
+		stats.InProgress = stats.Total - stats.Learning - stats.Mastered
 
-		fmt.Println("📊 Statistics")
-		fmt.Println("-------------")
-		fmt.Printf("Total Problems: %d\n", total)
-		fmt.Printf("Due (Approx):   %d\n", due)
-		fmt.Printf("Learning (<7d): %d\n", learning)
-		fmt.Printf("Mastered (>30d): %d\n", mastered)
-		fmt.Printf("In Progress:    %d\n", total - learning - mastered)
+		sched, err := loadScheduler(store)
+		if err != nil {
+			return fmt.Errorf("error loading scheduler: %w", err)
+		}
+		if sched.Name() == algorithm.LeitnerName {
+			stats.Boxes = boxCounts(problems)
+		}
+
+		return emit(stats, func() {
+			fmt.Println("📊 Statistics")
+			fmt.Println("-------------")
+			fmt.Printf("Total Problems: %d\n", stats.Total)
+			fmt.Printf("Due Today:      %d\n", stats.Due)
+			fmt.Printf("Learning (<7d): %d\n", stats.Learning)
+			fmt.Printf("Mastered (>30d): %d\n", stats.Mastered)
+			fmt.Printf("In Progress:    %d\n", stats.InProgress)
+			if stats.Boxes != nil {
+				printBoxes(stats.Boxes)
+			}
+		})
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(statsCmd)
 }
+
+// problemStats is the output of stats. Mastered problems have an
+// interval over 30 days and learning ones under 7.
+type problemStats struct {
+	Total      int        `json:"total"`
+	Due        int        `json:"due"`
+	Learning   int        `json:"learning"`
+	Mastered   int        `json:"mastered"`
+	InProgress int        `json:"in_progress"`
+	Boxes      []boxCount `json:"boxes,omitempty"` // Only under the Leitner scheduler
+}
+
+// boxCount is the number of problems in a Leitner box, 0 being new
+// problems not yet placed in one.
+type boxCount struct {
+	Box   int `json:"box"`
+	Count int `json:"count"`
+}
+
+// boxCounts counts the problems in each Leitner box, listing the new ones
+// only if there are any.
+func boxCounts(problems []models.Problem) []boxCount {
+	counts := make(map[int]int)
+	maxBox := 0
+	for _, p := range problems {
+		counts[p.Box]++
+		maxBox = max(maxBox, p.Box)
+	}
+	boxes := []boxCount{}
+	if counts[0] > 0 {
+		boxes = append(boxes, boxCount{0, counts[0]})
+	}
+	for box := 1; box <= maxBox; box++ {
+		boxes = append(boxes, boxCount{box, counts[box]})
+	}
+	return boxes
+}
+
+// printBoxes shows how many problems sit in each Leitner box.
+func printBoxes(boxes []boxCount) {
+	fmt.Println("\n📦 Leitner Boxes")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Box\tCount\t")
+	fmt.Fprintln(w, "---\t-----\t")
+	for _, b := range boxes {
+		box := fmt.Sprint(b.Box)
+		if b.Box == 0 {
+			box = "new"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", box, b.Count, strings.Repeat("█", b.Count))
+	}
+	w.Flush()
+}
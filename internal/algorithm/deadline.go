@@ -0,0 +1,70 @@
+package algorithm
+
+import (
+	"math"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// Deadline wraps a scheduler to prepare for a fixed date, such as an
+// interview. Until Date, day intervals are capped at the days left divided
+// by Reviews, so every problem is seen at least Reviews more times before
+// the date however well it is known. Reviews bunch up as the date nears.
+type Deadline struct {
+	Scheduler
+	Date    time.Time
+	Reviews int
+}
+
+// WithDeadline caps the intervals of s so each problem gets at least
+// reviews more reviews before date.
+func WithDeadline(s Scheduler, date time.Time, reviews int) Scheduler {
+	return Deadline{Scheduler: s, Date: date, Reviews: max(reviews, 1)}
+}
+
+func (d Deadline) Init(p models.Problem, now time.Time) models.Problem {
+	return d.limit(d.Scheduler.Init(p, now))
+}
+
+func (d Deadline) Review(p models.Problem, quality int, now time.Time) models.Problem {
+	return d.limit(d.Scheduler.Review(p, quality, now))
+}
+
+// DaysLeft returns the number of days from t until the deadline, counting
+// a partial day as a whole one, or 0 once it has passed.
+func (d Deadline) DaysLeft(t time.Time) int {
+	if !t.Before(d.Date) {
+		return 0
+	}
+	return int(math.Ceil(d.Date.Sub(t).Hours() / 24))
+}
+
+// IntervalLimit reports the deadline cap for a problem last reviewed at
+// p.LastReviewed, or a tighter one of the wrapped scheduler.
+func (d Deadline) IntervalLimit(p models.Problem) int {
+	limit := 0
+	if left := d.DaysLeft(p.LastReviewed); left > 0 {
+		limit = max(1, left/d.Reviews)
+	}
+	if inner := intervalLimit(d.Scheduler, p); inner > 0 && (limit == 0 || inner < limit) {
+		return inner
+	}
+	return limit
+}
+
+func (d Deadline) limit(p models.Problem) models.Problem {
+	if limit := d.IntervalLimit(p); limit > 0 {
+		return capInterval(p, limit)
+	}
+	return p
+}
+
+func (d Deadline) WithParams(params []float64) (Scheduler, error) {
+	inner, err := d.Scheduler.WithParams(params)
+	if err != nil {
+		return nil, err
+	}
+	d.Scheduler = inner
+	return d, nil
+}
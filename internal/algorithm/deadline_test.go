@@ -0,0 +1,51 @@
+package algorithm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+)
+
+func TestIntervalCaps(t *testing.T) {
+	sm2 := algorithm.NewSM2()
+	for _, tt := range []struct {
+		name     string
+		sched    algorithm.Scheduler
+		interval int
+	}{
+		// Unbounded, a good review of a 20 day interval gives 50 days.
+		{"no cap", sm2, 50},
+		{"max interval", algorithm.WithMaxInterval(sm2, 30), 30},
+		{"max interval above", algorithm.WithMaxInterval(sm2, 60), 50},
+		{"deadline", algorithm.WithDeadline(sm2, now.AddDate(0, 0, 30), 3), 10},
+		{"deadline with one review", algorithm.WithDeadline(sm2, now.AddDate(0, 0, 30), 0), 30},
+		{"deadline days away", algorithm.WithDeadline(sm2, now.AddDate(0, 0, 2), 3), 1},
+		{"deadline passed", algorithm.WithDeadline(sm2, now, 3), 50},
+		{"tighter max interval", algorithm.WithDeadline(algorithm.WithMaxInterval(sm2, 5), now.AddDate(0, 0, 30), 3), 5},
+		{"looser max interval", algorithm.WithDeadline(algorithm.WithMaxInterval(sm2, 20), now.AddDate(0, 0, 30), 3), 10},
+	} {
+		checkDue(t, tt.name, tt.sched.Review(learned(20, 2.5, 20), 4, now), tt.interval)
+	}
+
+	p := algorithm.WithMaxInterval(algorithm.WithSeeds(sm2, seeded.DifficultyEase, [5]int{9, 9, 9, 9, 9}), 7).Init(learned(0, 0, 0), now)
+	checkDue(t, "Init", p, 7)
+}
+
+func TestDeadlineDaysLeft(t *testing.T) {
+	d := algorithm.Deadline{Date: now.AddDate(0, 0, 30), Reviews: 3}
+	for _, tt := range []struct {
+		at   time.Time
+		want int
+	}{
+		{now, 30},
+		{now.Add(-time.Hour), 31},
+		{now.AddDate(0, 0, 29).Add(time.Hour), 1},
+		{d.Date, 0},
+		{d.Date.Add(time.Hour), 0},
+	} {
+		if got := d.DaysLeft(tt.at); got != tt.want {
+			t.Errorf("DaysLeft(%v): got %d, want %d", tt.at, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package algorithm
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// DefaultQualities is the quality distribution assumed when there is no
+// review history to learn it from.
+var DefaultQualities = [6]float64{0.02, 0.03, 0.05, 0.2, 0.4, 0.3}
+
+// Forecast simulates future reviews to project the daily workload.
+type Forecast struct {
+	Days      int            // Number of study days to project, starting today
+	NewPerDay int            // New problems assumed to be added each day
+	Qualities [6]float64     // Probability of each review quality 0-5
+	Runs      int            // Simulations to average over
+	Calendar  clock.Calendar // Decides where each study day starts
+	Rand      *rand.Rand
+}
+
+// QualityDistribution returns the share of reviews given each quality,
+// or DefaultQualities if there are none.
+func QualityDistribution(reviews []models.Review) [6]float64 {
+	var dist [6]float64
+	for _, r := range reviews {
+		if r.Quality >= 0 && r.Quality <= 5 {
+			dist[r.Quality]++
+		}
+	}
+	total := 0.0
+	for _, n := range dist {
+		total += n
+	}
+	if total == 0 {
+		return DefaultQualities
+	}
+	for i := range dist {
+		dist[i] /= total
+	}
+	return dist
+}
+
+// Run walks s forward from now, reviewing every problem on the day it falls
+// due with a quality drawn from f.Qualities, and returns the average number
+// of problems due on each day.
+func (f Forecast) Run(s Scheduler, problems []models.Problem, now time.Time) []float64 {
+	runs := f.Runs
+	if runs < 1 {
+		runs = 1
+	}
+	due := make([]float64, f.Days)
+	for run := 0; run < runs; run++ {
+		for day, n := range f.simulate(s, problems, now) {
+			due[day] += float64(n) / float64(runs)
+		}
+	}
+	return due
+}
+
+func (f Forecast) simulate(s Scheduler, problems []models.Problem, now time.Time) []int {
+	active := make([]models.Problem, 0, len(problems))
+	for _, p := range problems {
+		if !p.Suspended {
+			active = append(active, p)
+		}
+	}
+
+	due := make([]int, f.Days)
+	today := f.Calendar.DayStart(now)
+	for day := 0; day < f.Days; day++ {
+		start := today.AddDate(0, 0, day)
+		end := start.AddDate(0, 0, 1)
+		at := start
+		if day == 0 {
+			at = now
+		}
+
+		for i := 0; i < f.NewPerDay; i++ {
+			active = append(active, s.Init(models.Problem{}, at))
+		}
+
+		for i := range active {
+			if !active[i].NextReview.Before(end) {
+				continue
+			}
+			due[day]++
+			// Keep reviewing through any learning steps that fall due the same day.
+			p := active[i]
+			for reviews := 0; p.NextReview.Before(end) && reviews < 10; reviews++ {
+				reviewAt := at
+				if p.NextReview.After(reviewAt) {
+					reviewAt = p.NextReview
+				}
+				p = s.Review(p, f.quality(), reviewAt)
+			}
+			active[i] = p
+		}
+	}
+	return due
+}
+
+func (f Forecast) quality() int {
+	pick := f.Rand.Float64()
+	for q, share := range f.Qualities {
+		if pick < share {
+			return q
+		}
+		pick -= share
+	}
+	return 5
+}
@@ -0,0 +1,52 @@
+package algorithm_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+func TestQualityDistribution(t *testing.T) {
+	reviews := []models.Review{{Quality: 5}, {Quality: 5}, {Quality: 3}, {Quality: 0}, {Quality: 9}}
+	if got, want := algorithm.QualityDistribution(reviews), [6]float64{0.25, 0, 0, 0.25, 0, 0.5}; got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := algorithm.QualityDistribution(nil); got != algorithm.DefaultQualities {
+		t.Errorf("no reviews: got %v, want %v", got, algorithm.DefaultQualities)
+	}
+}
+
+func TestForecast(t *testing.T) {
+	tomorrow := learned(1, 2.5, 0) // Recalled perfectly, it is next due in six days.
+	suspended := learned(1, 2.5, 0)
+	suspended.Suspended = true
+	for _, tt := range []struct {
+		name     string
+		problems []models.Problem
+		perDay   int
+		want     []float64
+	}{
+		{"nothing", nil, 0, []float64{0, 0, 0}},
+		{"due tomorrow", []models.Problem{tomorrow}, 0, []float64{0, 1, 0}},
+		{"overdue", []models.Problem{learned(1, 2.5, 3)}, 0, []float64{1, 0, 0}},
+		{"suspended", []models.Problem{tomorrow, suspended}, 0, []float64{0, 1, 0}},
+		// Each day's new problem is due the next.
+		{"new problems", []models.Problem{tomorrow}, 1, []float64{0, 2, 1}},
+	} {
+		f := algorithm.Forecast{
+			Days:      3,
+			NewPerDay: tt.perDay,
+			Qualities: [6]float64{0, 0, 0, 0, 0, 1},
+			Calendar:  clock.Calendar{Location: time.UTC},
+			Rand:      rand.New(rand.NewSource(1)),
+		}
+		if got := f.Run(algorithm.NewSM2(), tt.problems, now); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
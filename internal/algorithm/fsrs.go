@@ -0,0 +1,195 @@
+package algorithm
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// FSRS forgetting curve constants (FSRS-4.5).
+const (
+	fsrsDecay  = -0.5
+	fsrsFactor = 19.0 / 81.0
+
+	DefaultRequestRetention = 0.9
+)
+
+// DefaultFSRSWeights are the published FSRS-4.5 default parameters.
+var DefaultFSRSWeights = [17]float64{
+	0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031,
+	1.6474, 0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+}
+
+// FSRS is the Free Spaced Repetition Scheduler. It models each problem's
+// memory with a stability (days until recall probability drops to 90%)
+// and a difficulty (1-10), and schedules the next review for when recall
+// is predicted to fall to RequestRetention.
+type FSRS struct {
+	Weights          [17]float64
+	RequestRetention float64
+}
+
+// NewFSRS returns an FSRS scheduler with the default weights.
+func NewFSRS() FSRS {
+	return FSRS{Weights: DefaultFSRSWeights, RequestRetention: DefaultRequestRetention}
+}
+
+func (FSRS) Name() string { return FSRSName }
+
+// Init sets the problem up as a new FSRS card, due tomorrow like SM-2.
+func (f FSRS) Init(p models.Problem, now time.Time) models.Problem {
+	p.EaseFactor = InitialEaseFactor
+	p.Stability = 0
+	p.FSRSDifficulty = 0
+	p.Lapses = 0
+	p.Interval = InitialInterval
+	p.LastReviewed = now
+	p.NextReview = p.LastReviewed.AddDate(0, 0, InitialInterval)
+	return p
+}
+
+// Review updates stability and difficulty from a review of the given quality.
+// Problems without FSRS state are seeded from their SM-2 interval, so
+// switching schedulers doesn't throw away existing progress.
+func (f FSRS) Review(p models.Problem, quality int, now time.Time) models.Problem {
+	g := fsrsGrade(quality)
+
+	if p.Stability <= 0 {
+		p = f.seed(p)
+	}
+
+	if p.Stability <= 0 {
+		p.Stability = f.initStability(g)
+		p.FSRSDifficulty = f.initDifficulty(g)
+	} else {
+		// Recall is judged against the time that actually passed, so overdue
+		// successes earn more stability and early reviews earn little.
+		r := f.retrievability(ElapsedDays(p, now), p.Stability)
+		if g == 1 {
+			p.Lapses++
+			p.Stability = f.forgetStability(p.FSRSDifficulty, p.Stability, r)
+		} else {
+			p.Stability = f.recallStability(p.FSRSDifficulty, p.Stability, r, g)
+		}
+		p.FSRSDifficulty = f.nextDifficulty(p.FSRSDifficulty, g)
+	}
+
+	p.Interval = f.nextInterval(p.Stability)
+	p.LastReviewed = now
+	p.NextReview = p.LastReviewed.AddDate(0, 0, p.Interval)
+	return p
+}
+
+// seed derives FSRS state from SM-2 state for problems that have already
+// progressed past their first interval.
+func (f FSRS) seed(p models.Problem) models.Problem {
+	if p.Interval <= InitialInterval {
+		return p
+	}
+	p.Stability = float64(p.Interval)
+	// Map the SM-2 ease range (1.3 hard .. 2.5+ easy) onto FSRS difficulty.
+	p.FSRSDifficulty = clamp(10-(p.EaseFactor-1.3)*5, 1, 10)
+	return p
+}
+
+// fsrsGrade maps the 0-5 quality scale onto FSRS ratings:
+// 1 again, 2 hard, 3 good, 4 easy.
+func fsrsGrade(quality int) int {
+	switch {
+	case quality < 3:
+		return 1
+	case quality == 3:
+		return 2
+	case quality == 4:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func (f FSRS) retrievability(elapsedDays, stability float64) float64 {
+	return math.Pow(1+fsrsFactor*elapsedDays/stability, fsrsDecay)
+}
+
+func (f FSRS) nextInterval(stability float64) int {
+	retention := f.RequestRetention
+	if retention <= 0 || retention >= 1 {
+		retention = DefaultRequestRetention
+	}
+	days := stability / fsrsFactor * (math.Pow(retention, 1/fsrsDecay) - 1)
+	interval := int(math.Round(days))
+	if interval < 1 {
+		interval = 1
+	}
+	return interval
+}
+
+func (f FSRS) initStability(g int) float64 {
+	return math.Max(f.Weights[g-1], 0.1)
+}
+
+func (f FSRS) initDifficulty(g int) float64 {
+	return clamp(f.Weights[4]-float64(g-3)*f.Weights[5], 1, 10)
+}
+
+func (f FSRS) nextDifficulty(d float64, g int) float64 {
+	next := d - f.Weights[6]*float64(g-3)
+	// Mean reversion towards the difficulty of a "good" first review.
+	next = f.Weights[7]*f.initDifficulty(3) + (1-f.Weights[7])*next
+	return clamp(next, 1, 10)
+}
+
+func (f FSRS) recallStability(d, s, r float64, g int) float64 {
+	hardPenalty, easyBonus := 1.0, 1.0
+	if g == 2 {
+		hardPenalty = f.Weights[15]
+	}
+	if g == 4 {
+		easyBonus = f.Weights[16]
+	}
+	return s * (1 + math.Exp(f.Weights[8])*(11-d)*math.Pow(s, -f.Weights[9])*
+		(math.Exp((1-r)*f.Weights[10])-1)*hardPenalty*easyBonus)
+}
+
+func (f FSRS) forgetStability(d, s, r float64) float64 {
+	next := f.Weights[11] * math.Pow(d, -f.Weights[12]) *
+		(math.Pow(s+1, f.Weights[13]) - 1) * math.Exp((1-r)*f.Weights[14])
+	return math.Min(next, s)
+}
+
+// Retrievability is the predicted probability of recalling the problem at now.
+func (f FSRS) Retrievability(p models.Problem, now time.Time) float64 {
+	p = f.seed(p)
+	if p.Stability <= 0 {
+		return 1
+	}
+	return f.retrievability(ElapsedDays(p, now), p.Stability)
+}
+
+func (f FSRS) Params() []float64 {
+	return append([]float64(nil), f.Weights[:]...)
+}
+
+func (FSRS) ParamBounds() [][2]float64 {
+	return [][2]float64{
+		{0.1, 100}, {0.1, 100}, {0.1, 100}, {0.1, 100},
+		{1, 10}, {0.1, 5}, {0.1, 5}, {0, 0.75},
+		{0, 4}, {0, 0.8}, {0.01, 3},
+		{0.5, 5}, {0.01, 0.2}, {0.01, 0.9}, {0.01, 2},
+		{0, 1}, {1, 6},
+	}
+}
+
+func (f FSRS) WithParams(params []float64) (Scheduler, error) {
+	if len(params) != len(f.Weights) {
+		return nil, fmt.Errorf("fsrs: expected %d parameters, got %d", len(f.Weights), len(params))
+	}
+	copy(f.Weights[:], params)
+	return f, nil
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
@@ -0,0 +1,118 @@
+package algorithm_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// card returns a problem with FSRS state, last reviewed elapsed days
+// before now.
+func card(stability, difficulty float64, elapsed int) models.Problem {
+	p := learned(int(math.Round(stability)), algorithm.InitialEaseFactor, elapsed)
+	p.Stability = stability
+	p.FSRSDifficulty = difficulty
+	return p
+}
+
+func TestFSRSFirstReview(t *testing.T) {
+	f := algorithm.NewFSRS()
+	w := algorithm.DefaultFSRSWeights
+	for _, tt := range []struct {
+		quality    int
+		stability  float64
+		difficulty float64
+		interval   int
+	}{
+		{0, w[0], w[4] + 2*w[5], 1},
+		{3, w[1], w[4] + w[5], 1},
+		{4, w[2], w[4], 4},
+		{5, w[3], w[4] - w[5], 14},
+	} {
+		p := f.Review(f.Init(models.Problem{}, now.AddDate(0, 0, -1)), tt.quality, now)
+		if math.Abs(p.Stability-tt.stability) > 1e-9 || math.Abs(p.FSRSDifficulty-tt.difficulty) > 1e-9 || p.Lapses != 0 {
+			t.Errorf("quality %d: got stability %v, difficulty %v and %d lapses, want %v, %v and 0",
+				tt.quality, p.Stability, p.FSRSDifficulty, p.Lapses, tt.stability, tt.difficulty)
+		}
+		checkDue(t, "first review", p, tt.interval)
+	}
+}
+
+func TestFSRSReview(t *testing.T) {
+	f := algorithm.NewFSRS()
+	stability := func(p models.Problem, quality int) float64 { return f.Review(p, quality, now).Stability }
+
+	// Recalling a problem grows its stability, more so the later it is and
+	// the easier it felt.
+	early, onTime, overdue := stability(card(10, 5, 2), 4), stability(card(10, 5, 10), 4), stability(card(10, 5, 30), 4)
+	if !(10 < early && early < onTime && onTime < overdue) {
+		t.Errorf("got stability %v early, %v on time and %v overdue, want 10 < early < on time < overdue", early, onTime, overdue)
+	}
+	hard, good, easy := stability(card(10, 5, 10), 3), stability(card(10, 5, 10), 4), stability(card(10, 5, 10), 5)
+	if !(10 < hard && hard < good && good < easy) {
+		t.Errorf("got stability %v hard, %v good and %v easy, want 10 < hard < good < easy", hard, good, easy)
+	}
+
+	// At the default 90% retention the interval is the stability.
+	p := f.Review(card(10, 5, 10), 4, now)
+	checkDue(t, "good review", p, int(math.Round(p.Stability)))
+
+	lapsed := f.Review(card(20, 5, 20), 0, now)
+	if lapsed.Lapses != 1 || lapsed.Stability >= 20 || lapsed.Interval >= 20 || lapsed.FSRSDifficulty <= 5 {
+		t.Errorf("lapse: got %d lapses, stability %v, interval %d and difficulty %v, want 1, below 20, below 20 and above 5",
+			lapsed.Lapses, lapsed.Stability, lapsed.Interval, lapsed.FSRSDifficulty)
+	}
+
+	p = card(10, 9, 10)
+	for range 20 {
+		p = f.Review(p, 0, now)
+	}
+	if p.FSRSDifficulty < 1 || p.FSRSDifficulty > 10 {
+		t.Errorf("after repeated lapses: got difficulty %v, want it within 1-10", p.FSRSDifficulty)
+	}
+}
+
+func TestFSRSRequestRetention(t *testing.T) {
+	strict := algorithm.NewFSRS()
+	strict.RequestRetention = 0.95
+	if got, normal := strict.Review(card(10, 5, 10), 4, now).Interval, algorithm.NewFSRS().Review(card(10, 5, 10), 4, now).Interval; got >= normal {
+		t.Errorf("retention 0.95: got interval %d, want it shorter than %d at 0.9", got, normal)
+	}
+}
+
+// Problems scheduled by SM-2 keep their progress when switched to FSRS.
+func TestFSRSSeedsFromSM2(t *testing.T) {
+	f := algorithm.NewFSRS()
+	p := learned(10, 2.5, 10)
+	if r := f.Retrievability(p, now); math.Abs(r-0.9) > 1e-9 {
+		t.Errorf("Retrievability of an SM-2 problem when due: got %v, want 0.9", r)
+	}
+	p = f.Review(p, 4, now)
+	if p.Stability <= 10 || math.Abs(p.FSRSDifficulty-4) > 0.1 {
+		t.Errorf("got stability %v and difficulty %v, want above 10 and about 4", p.Stability, p.FSRSDifficulty)
+	}
+	if r := f.Retrievability(learned(1, 2.5, 1), now); r != 1 {
+		t.Errorf("Retrievability of an unseeded problem: got %v, want 1", r)
+	}
+}
+
+func TestFSRSWithParams(t *testing.T) {
+	f := algorithm.NewFSRS()
+	params := f.Params()
+	params[2] = 8
+	next, err := f.WithParams(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := next.Review(next.Init(models.Problem{}, now.AddDate(0, 0, -1)), 4, now); p.Stability != 8 {
+		t.Errorf("got first good stability %v, want 8", p.Stability)
+	}
+	if _, err := f.WithParams(params[:5]); err == nil {
+		t.Error("WithParams with 5 parameters: got no error")
+	}
+	if len(f.ParamBounds()) != len(f.Params()) {
+		t.Errorf("got %d bounds for %d parameters", len(f.ParamBounds()), len(f.Params()))
+	}
+}
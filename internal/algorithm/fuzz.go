@@ -0,0 +1,106 @@
+package algorithm
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// DueLoad reports how many problems are already due on the study day
+// containing t.
+type DueLoad func(t time.Time) int
+
+// Fuzz wraps a scheduler and randomly spreads each new day interval over a
+// small window, so problems added together drift apart instead of staying
+// clumped on the same days. When Load is set, days in the window that
+// already have many problems due are less likely to be picked.
+type Fuzz struct {
+	Scheduler
+	Rand *rand.Rand
+	Load DueLoad
+}
+
+// WithFuzz wraps s with interval fuzz, balanced against load if it is not nil.
+func WithFuzz(s Scheduler, rng *rand.Rand, load DueLoad) Scheduler {
+	return Fuzz{Scheduler: s, Rand: rng, Load: load}
+}
+
+func (f Fuzz) Review(p models.Problem, quality int, now time.Time) models.Problem {
+	p = f.Scheduler.Review(p, quality, now)
+	if p.State.InLearning() || p.Suspended {
+		return p
+	}
+
+	lo, hi := FuzzRange(p.Interval)
+	if limit := intervalLimit(f.Scheduler, p); limit > 0 {
+		hi = min(hi, limit)
+		lo = min(lo, hi)
+	}
+	if lo == hi {
+		return p
+	}
+
+	weights := make([]float64, hi-lo+1)
+	total := 0.0
+	for i := range weights {
+		weights[i] = 1
+		if f.Load != nil {
+			// Inverse square of the load strongly favours lighter days.
+			due := float64(f.Load(p.LastReviewed.AddDate(0, 0, lo+i)))
+			weights[i] = 1 / ((due + 1) * (due + 1))
+		}
+		total += weights[i]
+	}
+
+	pick := f.Rand.Float64() * total
+	interval := hi
+	for i, w := range weights {
+		if pick < w {
+			interval = lo + i
+			break
+		}
+		pick -= w
+	}
+
+	p.Interval = interval
+	p.NextReview = p.LastReviewed.AddDate(0, 0, interval)
+	return p
+}
+
+// FuzzRange returns the window of days an interval may be moved within:
+// about 15% for short intervals, tapering to 5% beyond 20 days. Intervals
+// under three days are not fuzzed.
+func FuzzRange(interval int) (lo, hi int) {
+	if interval < 3 {
+		return interval, interval
+	}
+	iv := float64(interval)
+	delta := 1.0 + 0.15*(math.Min(iv, 7)-2.5)
+	if iv > 7 {
+		delta += 0.1 * (math.Min(iv, 20) - 7)
+	}
+	if iv > 20 {
+		delta += 0.05 * (iv - 20)
+	}
+	lo = int(math.Round(iv - delta))
+	hi = int(math.Round(iv + delta))
+	if lo < 2 {
+		lo = 2
+	}
+	return lo, hi
+}
+
+func (f Fuzz) IntervalLimit(p models.Problem) int {
+	return intervalLimit(f.Scheduler, p)
+}
+
+func (f Fuzz) WithParams(params []float64) (Scheduler, error) {
+	inner, err := f.Scheduler.WithParams(params)
+	if err != nil {
+		return nil, err
+	}
+	f.Scheduler = inner
+	return f, nil
+}
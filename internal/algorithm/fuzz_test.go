@@ -0,0 +1,77 @@
+package algorithm_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+)
+
+func TestFuzzRange(t *testing.T) {
+	for _, tt := range []struct{ interval, lo, hi int }{
+		{0, 0, 0}, {1, 1, 1}, {2, 2, 2}, {3, 2, 4}, {7, 5, 9},
+		{10, 8, 12}, {20, 17, 23}, {100, 93, 107},
+	} {
+		if lo, hi := algorithm.FuzzRange(tt.interval); lo != tt.lo || hi != tt.hi {
+			t.Errorf("FuzzRange(%d): got %d-%d, want %d-%d", tt.interval, lo, hi, tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestFuzzReview(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range []struct {
+		name   string
+		sched  algorithm.Scheduler
+		lo, hi int
+	}{
+		// Unfuzzed, a good review of a four day interval gives ten days.
+		{"unbounded", algorithm.NewSM2(), 8, 12},
+		{"capped", algorithm.WithMaxInterval(algorithm.NewSM2(), 10), 8, 10},
+		{"capped at five days", algorithm.WithMaxInterval(algorithm.NewSM2(), 5), 4, 5},
+	} {
+		s := algorithm.WithFuzz(tt.sched, rng, nil)
+		seen := map[int]bool{}
+		for range 200 {
+			p := s.Review(learned(4, 2.5, 4), 4, now)
+			if p.Interval < tt.lo || p.Interval > tt.hi || !p.NextReview.Equal(now.AddDate(0, 0, p.Interval)) {
+				t.Fatalf("%s: got interval %d due %v, want %d-%d days after now", tt.name, p.Interval, p.NextReview, tt.lo, tt.hi)
+			}
+			seen[p.Interval] = true
+		}
+		if len(seen) != tt.hi-tt.lo+1 {
+			t.Errorf("%s: got intervals %v, want every day of %d-%d", tt.name, seen, tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestFuzzLearningSteps(t *testing.T) {
+	s := algorithm.WithFuzz(algorithm.WithSteps(algorithm.NewSM2(), nil, []time.Duration{10 * time.Minute}), rand.New(rand.NewSource(1)), nil)
+	for range 20 {
+		if p := s.Review(learned(10, 2.5, 10), 1, now); !p.NextReview.Equal(now.Add(10 * time.Minute)) {
+			t.Fatalf("lapse: got due %v, want the relearning step at %v", p.NextReview, now.Add(10*time.Minute))
+		}
+	}
+}
+
+// With load balancing, an empty day in the window wins over full ones.
+func TestFuzzLoad(t *testing.T) {
+	light := now.AddDate(0, 0, 9)
+	load := func(day time.Time) int {
+		if day.Equal(light) {
+			return 0
+		}
+		return 100
+	}
+	s := algorithm.WithFuzz(algorithm.NewSM2(), rand.New(rand.NewSource(1)), load)
+	picked := 0
+	for range 1000 {
+		if p := s.Review(learned(4, 2.5, 4), 4, now); p.NextReview.Equal(light) {
+			picked++
+		}
+	}
+	if picked < 950 {
+		t.Errorf("got the light day %d times out of 1000, want at least 950", picked)
+	}
+}
@@ -0,0 +1,97 @@
+package algorithm
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// DefaultLeitnerIntervals are the review intervals in days of each box.
+var DefaultLeitnerIntervals = []int{1, 2, 4, 8, 16, 32}
+
+// Leitner is a transparent box system: a recalled problem moves up one box,
+// a forgotten one goes back to the first box, and each box has a fixed
+// interval.
+type Leitner struct {
+	Intervals []int // Interval in days of box 1, 2, ...
+}
+
+// NewLeitner returns a Leitner scheduler with the default boxes.
+func NewLeitner() Leitner {
+	return Leitner{Intervals: DefaultLeitnerIntervals}
+}
+
+func (Leitner) Name() string { return LeitnerName }
+
+// Init puts a new problem in the first box.
+func (l Leitner) Init(p models.Problem, now time.Time) models.Problem {
+	p.EaseFactor = InitialEaseFactor
+	p.Lapses = 0
+	p.Box = 1
+	p.Interval = l.Intervals[0]
+	p.LastReviewed = now
+	p.NextReview = now.AddDate(0, 0, p.Interval)
+	return p
+}
+
+// Review moves the problem up a box if recalled (quality >= 3) and back to
+// the first box otherwise.
+func (l Leitner) Review(p models.Problem, quality int, now time.Time) models.Problem {
+	p = l.Seed(p)
+	if quality >= 3 {
+		p.Box = min(p.Box+1, len(l.Intervals))
+	} else {
+		p.Box = 1
+		p.Lapses++
+	}
+	p.Interval = l.Intervals[p.Box-1]
+	p.LastReviewed = now
+	p.NextReview = now.AddDate(0, 0, p.Interval)
+	return p
+}
+
+// Seed places a problem that has no box yet, e.g. one scheduled by SM-2,
+// in the highest box whose interval it has already reached.
+func (l Leitner) Seed(p models.Problem) models.Problem {
+	if p.Box > 0 {
+		p.Box = min(p.Box, len(l.Intervals))
+		return p
+	}
+	p.Box = l.BoxFor(p.Interval)
+	return p
+}
+
+// BoxFor returns the highest box whose interval is at most interval days,
+// or 0 if it is shorter than the first box.
+func (l Leitner) BoxFor(interval int) int {
+	box := 0
+	for i, days := range l.Intervals {
+		if interval >= days {
+			box = i + 1
+		}
+	}
+	return box
+}
+
+// Retrievability assumes, like SM-2, that recall has dropped to 90% by the
+// time a box's interval has passed.
+func (Leitner) Retrievability(p models.Problem, now time.Time) float64 {
+	if p.Interval <= 0 {
+		return 1
+	}
+	return math.Pow(0.9, ElapsedDays(p, now)/float64(p.Interval))
+}
+
+// Params is empty: box intervals are chosen by the user, not fitted.
+func (Leitner) Params() []float64 { return nil }
+
+func (Leitner) ParamBounds() [][2]float64 { return nil }
+
+func (l Leitner) WithParams(params []float64) (Scheduler, error) {
+	if len(params) != 0 {
+		return nil, fmt.Errorf("leitner: expected no parameters, got %d", len(params))
+	}
+	return l, nil
+}
@@ -0,0 +1,56 @@
+package algorithm_test
+
+import (
+	"testing"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+func TestLeitnerReview(t *testing.T) {
+	l := algorithm.NewLeitner() // Boxes of 1, 2, 4, 8, 16 and 32 days
+	boxed := func(box int) models.Problem {
+		p := learned(l.Intervals[box-1], algorithm.InitialEaseFactor, l.Intervals[box-1])
+		p.Box = box
+		return p
+	}
+	for _, tt := range []struct {
+		name    string
+		p       models.Problem
+		quality int
+		box     int
+		lapses  int
+	}{
+		{"recalled", boxed(1), 3, 2, 0},
+		{"recalled in the middle", boxed(3), 5, 4, 0},
+		{"recalled in the top box", boxed(6), 4, 6, 0},
+		{"forgotten", boxed(5), 2, 1, 1},
+		{"seeded from SM-2", learned(10, 2.5, 10), 4, 5, 0},
+		{"seeded below the first box", learned(0, 2.5, 0), 4, 1, 0},
+		{"box beyond the last", func() models.Problem { p := boxed(6); p.Box = 9; return p }(), 4, 6, 0},
+	} {
+		p := l.Review(tt.p, tt.quality, now)
+		if p.Box != tt.box || p.Lapses != tt.lapses {
+			t.Errorf("%s: got box %d and %d lapses, want %d and %d", tt.name, p.Box, p.Lapses, tt.box, tt.lapses)
+		}
+		checkDue(t, tt.name, p, l.Intervals[tt.box-1])
+	}
+
+	p := l.Init(models.Problem{}, now)
+	if p.Box != 1 {
+		t.Errorf("Init: got box %d, want 1", p.Box)
+	}
+	checkDue(t, "Init", p, 1)
+}
+
+func TestLeitnerBoxFor(t *testing.T) {
+	l := algorithm.Leitner{Intervals: []int{1, 3, 7}}
+	for interval, want := range map[int]int{0: 0, 1: 1, 2: 1, 3: 2, 6: 2, 7: 3, 100: 3} {
+		if got := l.BoxFor(interval); got != want {
+			t.Errorf("BoxFor(%d): got %d, want %d", interval, got, want)
+		}
+	}
+	if _, err := l.WithParams([]float64{1}); err == nil {
+		t.Error("WithParams with a parameter: got no error")
+	}
+}
@@ -0,0 +1,119 @@
+package algorithm
+
+import (
+	"math"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// MinFitSamples is the number of graded reviews needed before fitting
+// parameters is considered meaningful.
+const MinFitSamples = 20
+
+// Evaluation summarizes how well a scheduler predicts a review history.
+type Evaluation struct {
+	Samples            int     // Reviews that had a prediction (every review after the first)
+	LogLoss            float64 // Mean binary cross-entropy of predicted recall
+	PredictedRetention float64 // Mean predicted recall probability
+	ActualRetention    float64 // Share of those reviews rated 3 or higher
+}
+
+// Evaluate replays each problem's review history through s and compares
+// the predicted recall probability before every review with whether the
+// review was actually recalled (quality >= 3). Histories must be ordered
+// by ReviewedAt.
+func Evaluate(s Scheduler, histories [][]models.Review) Evaluation {
+	var ev Evaluation
+	var recalled int
+	for _, history := range histories {
+		if len(history) == 0 {
+			continue
+		}
+		p := s.Init(models.Problem{}, history[0].ReviewedAt.AddDate(0, 0, -InitialInterval))
+		for i, r := range history {
+			if i > 0 {
+				pred := clamp(s.Retrievability(p, r.ReviewedAt), 1e-4, 1-1e-4)
+				ev.Samples++
+				ev.PredictedRetention += pred
+				if r.Quality >= 3 {
+					recalled++
+					ev.LogLoss -= math.Log(pred)
+				} else {
+					ev.LogLoss -= math.Log(1 - pred)
+				}
+			}
+			p = s.Review(p, r.Quality, r.ReviewedAt)
+		}
+	}
+	if ev.Samples > 0 {
+		n := float64(ev.Samples)
+		ev.LogLoss /= n
+		ev.PredictedRetention /= n
+		ev.ActualRetention = float64(recalled) / n
+	}
+	return ev
+}
+
+// Replay rebuilds p's schedule from scratch by initializing it just before
+// its first review and applying each review of history in order.
+func Replay(s Scheduler, p models.Problem, history []models.Review) models.Problem {
+	if len(history) == 0 {
+		return p
+	}
+	p = s.Init(p, history[0].ReviewedAt.AddDate(0, 0, -InitialInterval))
+	for _, r := range history {
+		p = s.Review(p, r.Quality, r.ReviewedAt)
+	}
+	return p
+}
+
+// Fit searches for the parameters of s that minimize the log loss of its
+// recall predictions over histories, using coordinate descent within each
+// parameter's bounds. It returns the fitted scheduler and its evaluation.
+func Fit(s Scheduler, histories [][]models.Review) (Scheduler, Evaluation, error) {
+	best := s
+	bestEval := Evaluate(s, histories)
+
+	params := s.Params()
+	bounds := s.ParamBounds()
+	steps := make([]float64, len(params))
+	for i, b := range bounds {
+		steps[i] = (b[1] - b[0]) / 10
+	}
+
+	for round := 0; round < 50; round++ {
+		improved := false
+		for i := range params {
+			for _, dir := range []float64{1, -1} {
+				candidate := append([]float64(nil), params...)
+				candidate[i] = clamp(candidate[i]+dir*steps[i], bounds[i][0], bounds[i][1])
+				if candidate[i] == params[i] {
+					continue
+				}
+				next, err := s.WithParams(candidate)
+				if err != nil {
+					return nil, Evaluation{}, err
+				}
+				ev := Evaluate(next, histories)
+				if ev.LogLoss < bestEval.LogLoss {
+					best, bestEval, params = next, ev, candidate
+					improved = true
+					break
+				}
+			}
+		}
+		if !improved {
+			converged := true
+			for i := range steps {
+				steps[i] /= 2
+				if steps[i] > (bounds[i][1]-bounds[i][0])/1000 {
+					converged = false
+				}
+			}
+			if converged {
+				break
+			}
+		}
+	}
+	return best, bestEval, nil
+}
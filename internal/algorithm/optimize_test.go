@@ -0,0 +1,96 @@
+package algorithm_test
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// histories returns review histories whose recall decays with the days
+// between reviews, generated from a fixed seed.
+func histories() [][]models.Review {
+	rng := rand.New(rand.NewSource(1))
+	var all [][]models.Review
+	for range 40 {
+		at := now.AddDate(0, -6, 0)
+		var history []models.Review
+		for range 8 {
+			gap := 1 + rng.Intn(20)
+			at = at.AddDate(0, 0, gap)
+			quality := 1
+			if rng.Float64() < math.Exp(-float64(gap)/40) {
+				quality = 3 + rng.Intn(3)
+			}
+			history = append(history, models.Review{Quality: quality, ReviewedAt: at})
+		}
+		all = append(all, history)
+	}
+	return all
+}
+
+func TestEvaluate(t *testing.T) {
+	h := histories()
+	ev := algorithm.Evaluate(algorithm.NewSM2(), append(h, nil))
+	if ev.Samples != 40*7 {
+		t.Errorf("got %d samples, want %d", ev.Samples, 40*7)
+	}
+	for name, v := range map[string]float64{"predicted": ev.PredictedRetention, "actual": ev.ActualRetention} {
+		if v <= 0 || v >= 1 {
+			t.Errorf("%s retention: got %v, want between 0 and 1", name, v)
+		}
+	}
+	if ev.LogLoss <= 0 {
+		t.Errorf("got log loss %v, want above 0", ev.LogLoss)
+	}
+	if ev := algorithm.Evaluate(algorithm.NewSM2(), nil); ev != (algorithm.Evaluation{}) {
+		t.Errorf("no histories: got %+v, want a zero evaluation", ev)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	s := algorithm.NewFSRS()
+	history := histories()[0]
+	want := s.Init(models.Problem{Name: "Two Sum"}, history[0].ReviewedAt.AddDate(0, 0, -1))
+	for _, r := range history {
+		want = s.Review(want, r.Quality, r.ReviewedAt)
+	}
+	if got := algorithm.Replay(s, models.Problem{Name: "Two Sum"}, history); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got := algorithm.Replay(s, learned(10, 2.5, 10), nil); !reflect.DeepEqual(got, learned(10, 2.5, 10)) {
+		t.Errorf("no history: got %+v, want the problem unchanged", got)
+	}
+}
+
+func TestFit(t *testing.T) {
+	h := histories()
+	for _, s := range []algorithm.Scheduler{algorithm.NewSM2(), algorithm.NewFSRS()} {
+		before := algorithm.Evaluate(s, h)
+		fitted, ev, err := algorithm.Fit(s, h)
+		if err != nil {
+			t.Fatalf("%s: %v", s.Name(), err)
+		}
+		if ev.LogLoss > before.LogLoss {
+			t.Errorf("%s: got log loss %v, want at most the initial %v", s.Name(), ev.LogLoss, before.LogLoss)
+		}
+		if again := algorithm.Evaluate(fitted, h); again != ev {
+			t.Errorf("%s: got evaluation %+v, but the fitted scheduler evaluates to %+v", s.Name(), ev, again)
+		}
+		bounds := fitted.ParamBounds()
+		for i, p := range fitted.Params() {
+			if p < bounds[i][0] || p > bounds[i][1] {
+				t.Errorf("%s: parameter %d is %v, outside %v", s.Name(), i, p, bounds[i])
+			}
+		}
+	}
+
+	// Leitner has nothing to fit.
+	l := algorithm.NewLeitner()
+	if _, ev, err := algorithm.Fit(l, h); err != nil || ev != algorithm.Evaluate(l, h) {
+		t.Errorf("Leitner: got %+v, %v, want its unchanged evaluation", ev, err)
+	}
+}
@@ -0,0 +1,178 @@
+package algorithm
+
+import (
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// Options are the user settings that shape a scheduler. Presets override
+// some of them for the problems they are assigned to.
+type Options struct {
+	MaxInterval     int     // Longest interval in days (0 for no limit)
+	EaseFloor       float64 // Lowest SM-2 ease
+	TargetRetention float64 // Recall probability to schedule reviews at
+	LearningSteps   []time.Duration
+	RelearningSteps []time.Duration
+
+	// Deadline, when set, caps intervals so each problem gets at least
+	// DeadlineReviews more reviews before it.
+	Deadline        time.Time
+	DeadlineReviews int
+
+	LeitnerIntervals        []int
+	DifficultyEase          [5]float64
+	DifficultyFirstInterval [5]int
+}
+
+// Configure applies opts to s and wraps it with the difficulty seeds,
+// interval limit and learning steps they describe.
+func Configure(s Scheduler, opts Options) Scheduler {
+	switch sched := s.(type) {
+	case SM2:
+		sched.EaseFloor = opts.EaseFloor
+		sched.TargetRetention = opts.TargetRetention
+		s = sched
+	case FSRS:
+		if opts.TargetRetention > 0 {
+			sched.RequestRetention = opts.TargetRetention
+		}
+		s = sched
+	case Leitner:
+		if len(opts.LeitnerIntervals) > 0 {
+			sched.Intervals = opts.LeitnerIntervals
+		}
+		s = sched
+	}
+
+	if _, ok := s.(SM2); ok {
+		// Only SM-2 is seeded: Leitner boxes have fixed intervals, and
+		// FSRS derives a new problem's stability and difficulty from its
+		// first grade, while a seeded interval would pass for SM-2 progress.
+		s = WithSeeds(s, opts.DifficultyEase, opts.DifficultyFirstInterval)
+	}
+	if opts.MaxInterval > 0 {
+		s = WithMaxInterval(s, opts.MaxInterval)
+	}
+	if !opts.Deadline.IsZero() {
+		s = WithDeadline(s, opts.Deadline, opts.DeadlineReviews)
+	}
+	return WithSteps(s, opts.LearningSteps, opts.RelearningSteps)
+}
+
+// IntervalLimiter is implemented by schedulers that cap day intervals, so
+// that wrappers adjusting intervals afterwards can stay within the cap.
+type IntervalLimiter interface {
+	// IntervalLimit returns the longest interval allowed for p, or 0 for
+	// no limit.
+	IntervalLimit(p models.Problem) int
+}
+
+// intervalLimit returns the interval cap s applies to p, or 0 for none.
+func intervalLimit(s Scheduler, p models.Problem) int {
+	if l, ok := s.(IntervalLimiter); ok {
+		return l.IntervalLimit(p)
+	}
+	return 0
+}
+
+// MaxInterval wraps a scheduler and caps every day interval it produces.
+type MaxInterval struct {
+	Scheduler
+	Days int
+}
+
+// WithMaxInterval caps the intervals of s at days.
+func WithMaxInterval(s Scheduler, days int) Scheduler {
+	return MaxInterval{Scheduler: s, Days: days}
+}
+
+func (m MaxInterval) Init(p models.Problem, now time.Time) models.Problem {
+	return m.limit(m.Scheduler.Init(p, now))
+}
+
+func (m MaxInterval) Review(p models.Problem, quality int, now time.Time) models.Problem {
+	return m.limit(m.Scheduler.Review(p, quality, now))
+}
+
+// IntervalLimit reports the cap, or a tighter one of the wrapped scheduler.
+func (m MaxInterval) IntervalLimit(p models.Problem) int {
+	if inner := intervalLimit(m.Scheduler, p); inner > 0 && inner < m.Days {
+		return inner
+	}
+	return m.Days
+}
+
+func (m MaxInterval) limit(p models.Problem) models.Problem {
+	return capInterval(p, m.Days)
+}
+
+// capInterval shortens a day interval longer than days.
+func capInterval(p models.Problem, days int) models.Problem {
+	if p.Interval > days {
+		p.Interval = days
+		p.NextReview = p.LastReviewed.AddDate(0, 0, days)
+	}
+	return p
+}
+
+func (m MaxInterval) WithParams(params []float64) (Scheduler, error) {
+	inner, err := m.Scheduler.WithParams(params)
+	if err != nil {
+		return nil, err
+	}
+	m.Scheduler = inner
+	return m, nil
+}
+
+// Presets picks a scheduler per problem: problems with a preset use that
+// preset's scheduler, everything else uses Default.
+type Presets struct {
+	Default  Scheduler
+	ByName   map[string]Scheduler
+	PresetOf func(p models.Problem) string
+}
+
+func (ps Presets) For(p models.Problem) Scheduler {
+	if s, ok := ps.ByName[ps.PresetOf(p)]; ok {
+		return s
+	}
+	return ps.Default
+}
+
+func (ps Presets) Name() string { return ps.Default.Name() }
+
+func (ps Presets) Init(p models.Problem, now time.Time) models.Problem {
+	return ps.For(p).Init(p, now)
+}
+
+func (ps Presets) Review(p models.Problem, quality int, now time.Time) models.Problem {
+	return ps.For(p).Review(p, quality, now)
+}
+
+func (ps Presets) Retrievability(p models.Problem, now time.Time) float64 {
+	return ps.For(p).Retrievability(p, now)
+}
+
+func (ps Presets) IntervalLimit(p models.Problem) int {
+	return intervalLimit(ps.For(p), p)
+}
+
+func (ps Presets) Params() []float64 { return ps.Default.Params() }
+
+func (ps Presets) ParamBounds() [][2]float64 { return ps.Default.ParamBounds() }
+
+// WithParams applies params to the default scheduler and every preset.
+func (ps Presets) WithParams(params []float64) (Scheduler, error) {
+	next := Presets{ByName: make(map[string]Scheduler, len(ps.ByName)), PresetOf: ps.PresetOf}
+	var err error
+	if next.Default, err = ps.Default.WithParams(params); err != nil {
+		return nil, err
+	}
+	for name, s := range ps.ByName {
+		if next.ByName[name], err = s.WithParams(params); err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
@@ -0,0 +1,50 @@
+package algorithm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+var seeded = algorithm.Options{
+	DifficultyEase:          [5]float64{2.8, 2.65, 2.5, 2.3, 2.1},
+	DifficultyFirstInterval: [5]int{3, 2, 1, 1, 1},
+}
+
+func TestConfigureSeedsSM2(t *testing.T) {
+	sched := algorithm.Configure(algorithm.NewSM2(), seeded)
+	for difficulty, want := range map[int]struct {
+		ease     float64
+		interval int
+	}{1: {2.8, 3}, 2: {2.65, 2}, 3: {2.5, 1}, 5: {2.1, 1}} {
+		p := sched.Init(models.Problem{Difficulty: difficulty}, now)
+		if p.EaseFactor != want.ease || p.Interval != want.interval || !p.NextReview.Equal(now.AddDate(0, 0, want.interval)) {
+			t.Errorf("difficulty %d: got ease %v and interval %d due %v, want %v and %d", difficulty, p.EaseFactor, p.Interval, p.NextReview, want.ease, want.interval)
+		}
+	}
+}
+
+// A new FSRS problem starts from its first grade whatever its difficulty,
+// rather than passing for an SM-2 problem that has progressed.
+func TestConfigureDoesNotSeedFSRS(t *testing.T) {
+	sched := algorithm.Configure(algorithm.NewFSRS(), seeded)
+	for _, difficulty := range []int{1, 2, 3, 5} {
+		p := sched.Init(models.Problem{Difficulty: difficulty}, now)
+		if p.Interval != algorithm.InitialInterval {
+			t.Errorf("difficulty %d: Init got interval %d, want %d", difficulty, p.Interval, algorithm.InitialInterval)
+		}
+		p = sched.Review(p, 0, now.Add(24*time.Hour))
+		if want := algorithm.DefaultFSRSWeights[0]; p.Stability != want || p.Lapses != 0 {
+			t.Errorf("difficulty %d: first review rated again got stability %v and %d lapses, want %v and 0", difficulty, p.Stability, p.Lapses, want)
+		}
+	}
+}
+
+func TestConfigureDoesNotSeedLeitner(t *testing.T) {
+	sched := algorithm.Configure(algorithm.NewLeitner(), seeded)
+	if p := sched.Init(models.Problem{Difficulty: 1}, now); p.Interval != algorithm.DefaultLeitnerIntervals[0] {
+		t.Errorf("Init got interval %d, want box 1's %d", p.Interval, algorithm.DefaultLeitnerIntervals[0])
+	}
+}
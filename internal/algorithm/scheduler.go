@@ -0,0 +1,53 @@
+package algorithm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// Names of the available schedulers, as stored in the database settings.
+const (
+	SM2Name     = "sm2"
+	FSRSName    = "fsrs"
+	LeitnerName = "leitner"
+
+	DefaultScheduler = SM2Name
+)
+
+// Scheduler decides when a problem should be reviewed next.
+type Scheduler interface {
+	// Name returns the identifier used to select this scheduler.
+	Name() string
+	// Init prepares a newly added problem.
+	Init(p models.Problem, now time.Time) models.Problem
+	// Review applies a review of the given quality (0-5) made at now.
+	Review(p models.Problem, quality int, now time.Time) models.Problem
+	// Retrievability predicts the probability of recalling p at now.
+	Retrievability(p models.Problem, now time.Time) float64
+	// Params returns the tunable parameters of the scheduler.
+	Params() []float64
+	// ParamBounds returns the allowed [min, max] range of each parameter.
+	ParamBounds() [][2]float64
+	// WithParams returns a copy of the scheduler using params.
+	WithParams(params []float64) (Scheduler, error)
+}
+
+// Names lists every available scheduler.
+func Names() []string {
+	return []string{SM2Name, FSRSName, LeitnerName}
+}
+
+// New returns the scheduler registered under name.
+func New(name string) (Scheduler, error) {
+	switch name {
+	case "", SM2Name:
+		return NewSM2(), nil
+	case FSRSName:
+		return NewFSRS(), nil
+	case LeitnerName:
+		return NewLeitner(), nil
+	}
+	return nil, fmt.Errorf("unknown scheduler %q", name)
+}
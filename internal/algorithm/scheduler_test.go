@@ -0,0 +1,50 @@
+package algorithm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// now is the fixed time the scheduler tests review at.
+var now = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+// learned returns a problem in review with the given interval and ease,
+// last reviewed elapsed days before now.
+func learned(interval int, ease float64, elapsed int) models.Problem {
+	last := now.AddDate(0, 0, -elapsed)
+	return models.Problem{
+		Name:         "Two Sum",
+		Difficulty:   3,
+		Interval:     interval,
+		EaseFactor:   ease,
+		LastReviewed: last,
+		NextReview:   last.AddDate(0, 0, interval),
+		State:        models.StateReview,
+	}
+}
+
+// checkDue reports an error unless p is due interval days after now.
+func checkDue(t *testing.T, name string, p models.Problem, interval int) {
+	t.Helper()
+	if p.Interval != interval || !p.NextReview.Equal(now.AddDate(0, 0, interval)) {
+		t.Errorf("%s: got interval %d due %v, want %d due %v", name, p.Interval, p.NextReview, interval, now.AddDate(0, 0, interval))
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range algorithm.Names() {
+		s, err := algorithm.New(name)
+		if err != nil || s.Name() != name {
+			t.Errorf("New(%q): got %v, %v", name, s, err)
+		}
+	}
+	if s, err := algorithm.New(""); err != nil || s.Name() != algorithm.DefaultScheduler {
+		t.Errorf(`New(""): got %v, %v, want the default scheduler`, s, err)
+	}
+	if _, err := algorithm.New("anki"); err == nil {
+		t.Error(`New("anki"): got no error`)
+	}
+}
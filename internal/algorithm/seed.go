@@ -0,0 +1,65 @@
+package algorithm
+
+import (
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// Seeds wraps a scheduler so a new problem's starting ease and first day
+// interval depend on its difficulty (1-5): harder problems start with a
+// lower ease and tighter spacing.
+type Seeds struct {
+	Scheduler
+	Ease          [5]float64 // Initial ease factor for difficulty 1..5
+	FirstInterval [5]int     // First interval in days for difficulty 1..5
+}
+
+// WithSeeds wraps s with difficulty-based starting values.
+func WithSeeds(s Scheduler, ease [5]float64, firstInterval [5]int) Scheduler {
+	return Seeds{Scheduler: s, Ease: ease, FirstInterval: firstInterval}
+}
+
+func (s Seeds) Init(p models.Problem, now time.Time) models.Problem {
+	p = s.Scheduler.Init(p, now)
+	i, ok := difficultyIndex(p.Difficulty)
+	if !ok {
+		return p
+	}
+	p.EaseFactor = s.Ease[i]
+	p.Interval = s.FirstInterval[i]
+	p.NextReview = now.AddDate(0, 0, p.Interval)
+	return p
+}
+
+// Review gives a problem graduating from its learning steps (which leave
+// the interval at zero) its difficulty's first interval.
+func (s Seeds) Review(p models.Problem, quality int, now time.Time) models.Problem {
+	first := p.Interval == 0 && quality >= 3
+	p = s.Scheduler.Review(p, quality, now)
+	if i, ok := difficultyIndex(p.Difficulty); ok && first {
+		p.Interval = s.FirstInterval[i]
+		p.NextReview = now.AddDate(0, 0, p.Interval)
+	}
+	return p
+}
+
+func (s Seeds) IntervalLimit(p models.Problem) int {
+	return intervalLimit(s.Scheduler, p)
+}
+
+func (s Seeds) WithParams(params []float64) (Scheduler, error) {
+	inner, err := s.Scheduler.WithParams(params)
+	if err != nil {
+		return nil, err
+	}
+	s.Scheduler = inner
+	return s, nil
+}
+
+func difficultyIndex(difficulty int) (int, bool) {
+	if difficulty < 1 || difficulty > 5 {
+		return 0, false
+	}
+	return difficulty - 1, true
+}
@@ -0,0 +1,35 @@
+package algorithm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// A problem leaving its learning steps gets its difficulty's first interval.
+func TestSeedsGraduation(t *testing.T) {
+	s := algorithm.WithSteps(
+		algorithm.WithSeeds(algorithm.NewSM2(), seeded.DifficultyEase, seeded.DifficultyFirstInterval),
+		[]time.Duration{10 * time.Minute}, nil)
+	for difficulty, want := range map[int]int{0: 1, 1: 3, 2: 2, 5: 1} {
+		p := s.Init(models.Problem{Difficulty: difficulty}, now)
+		p = s.Review(p, 4, now)
+		if p.State != models.StateReview {
+			t.Errorf("difficulty %d: got state %s, want review", difficulty, p.State)
+		}
+		checkDue(t, "graduating", p, want)
+	}
+}
+
+func TestSeedsUnknownDifficulty(t *testing.T) {
+	s := algorithm.WithSeeds(algorithm.NewSM2(), seeded.DifficultyEase, seeded.DifficultyFirstInterval)
+	for _, difficulty := range []int{0, 6} {
+		p := s.Init(models.Problem{Difficulty: difficulty}, now)
+		if p.EaseFactor != algorithm.InitialEaseFactor {
+			t.Errorf("difficulty %d: got ease %v, want %v", difficulty, p.EaseFactor, algorithm.InitialEaseFactor)
+		}
+		checkDue(t, "Init", p, algorithm.InitialInterval)
+	}
+}
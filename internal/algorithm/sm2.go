@@ -1,6 +1,7 @@
 package algorithm
 
 import (
+	"fmt"
 	"math"
 	"time"
 
@@ -11,11 +12,47 @@ import (
 const (
 	InitialInterval   = 1
 	InitialEaseFactor = 2.5
+	MinEaseFactor     = 1.3
 )
 
-// CalculateReview updates the problem based on the quality of the review session.
+// DefaultEaseDeltas are the ease factor changes of standard SM-2 for each
+// quality 0-5, i.e. 0.1 - (5-q) * (0.08 + (5-q)*0.02).
+var DefaultEaseDeltas = [6]float64{-0.8, -0.54, -0.32, -0.14, 0, 0.1}
+
+// SM2 is the classic SuperMemo-2 scheduler. EaseDeltas holds the ease
+// factor change applied for each quality rating.
+type SM2 struct {
+	EaseDeltas [6]float64
+	// EaseFloor is the lowest ease a problem can drop to (MinEaseFactor if zero).
+	EaseFloor float64
+	// TargetRetention scales intervals so recall is expected to have dropped
+	// to this probability when a problem is due (DefaultRequestRetention if zero).
+	TargetRetention float64
+}
+
+// NewSM2 returns an SM-2 scheduler with the standard ease deltas.
+func NewSM2() SM2 {
+	return SM2{EaseDeltas: DefaultEaseDeltas}
+}
+
+func (SM2) Name() string { return SM2Name }
+
+// Init sets default SM-2 values for a new problem.
+func (SM2) Init(p models.Problem, now time.Time) models.Problem {
+	p.EaseFactor = InitialEaseFactor
+	p.Interval = InitialInterval
+	p.Lapses = 0
+	p.LastReviewed = now
+	// Next review is initially tomorrow? Or today if we assume valid immediately.
+	// Typically immediate review -> then interval. But mostly for CLI we set it for 1 day later or same day.
+	// Let's set it to 1 day later for now as "Learning" phase usually implies reviewing next day.
+	p.NextReview = p.LastReviewed.AddDate(0, 0, InitialInterval)
+	return p
+}
+
+// Review updates the problem based on the quality of the review session.
 // quality: 0 (blackout) to 5 (perfect recollection)
-func CalculateReview(p models.Problem, quality int) models.Problem {
+func (s SM2) Review(p models.Problem, quality int, now time.Time) models.Problem {
 	if quality < 0 {
 		quality = 0
 	}
@@ -24,12 +61,15 @@ func CalculateReview(p models.Problem, quality int) models.Problem {
 	}
 
 	// 1. Calculate new Ease Factor
-	// EF' = EF + (0.1 - (5-q) * (0.08 + (5-q)*0.02))
-	// If EF goes below 1.3, set it to 1.3
-	q := float64(quality)
-	newEase := p.EaseFactor + (0.1 - (5-q)*(0.08+(5-q)*0.02))
-	if newEase < 1.3 {
-		newEase = 1.3
+	// EF' = EF + delta(q), by default delta(q) = 0.1 - (5-q) * (0.08 + (5-q)*0.02)
+	// If EF goes below the floor (1.3 by default), set it to the floor
+	newEase := p.EaseFactor + s.EaseDeltas[quality]
+	floor := s.EaseFloor
+	if floor == 0 {
+		floor = MinEaseFactor
+	}
+	if newEase < floor {
+		newEase = floor
 	}
 
 	// 2. Calculate new Interval
@@ -37,38 +77,100 @@ func CalculateReview(p models.Problem, quality int) models.Problem {
 	if quality < 3 {
 		// If the user failed (quality < 3), start over
 		newInterval = 1
+		p.Lapses++
 		// Optionally, we could reset interval to 1 but keep EF or reduce it.
 		// Standard SM-2 resets interval to 1.
 	} else {
 		if p.Interval == 0 {
 			newInterval = 1
-		} else if p.Interval == 1 {
-			newInterval = 6
 		} else {
-			newInterval = int(math.Ceil(float64(p.Interval) * newEase))
+			newInterval = sm2Interval(p.Interval, math.Round(ElapsedDays(p, now)), newEase*s.intervalModifier(), quality)
 		}
 	}
 
 	// Update the problem struct
 	p.EaseFactor = newEase
 	p.Interval = newInterval
-	p.LastReviewed = time.Now()
+	p.LastReviewed = now
 	p.NextReview = p.LastReviewed.AddDate(0, 0, newInterval)
 
 	return p
 }
 
-// InitProblem sets default values for a new problem
-func InitProblem(p models.Problem, initialEase float64) models.Problem {
-	if initialEase == 0 {
-		initialEase = InitialEaseFactor
+// sm2Interval grows a successful review's interval from the time that
+// actually passed rather than the time that was scheduled. An overdue
+// problem that was still recalled gets credit for the extra retention
+// (half of it for a hesitant quality 3), while an early review grows from
+// the shorter elapsed time and never shrinks the interval.
+func sm2Interval(scheduled int, elapsed, ease float64, quality int) int {
+	base := float64(scheduled)
+	switch {
+	case elapsed > base:
+		late := elapsed - base
+		if quality == 3 {
+			late /= 2
+		}
+		base += late
+	case elapsed < base:
+		base = elapsed
 	}
-	p.EaseFactor = initialEase
-	p.Interval = InitialInterval
-	p.LastReviewed = time.Now()
-	// Next review is initially tomorrow? Or today if we assume valid immediately.
-	// Typically immediate review -> then interval. But mostly for CLI we set it for 1 day later or same day.
-	// Let's set it to 1 day later for now as "Learning" phase usually implies reviewing next day.
-	p.NextReview = p.LastReviewed.AddDate(0, 0, InitialInterval)
-	return p
+
+	var next int
+	if scheduled == 1 {
+		// Second repetition: the standard six days, or more if it was overdue.
+		next = 6
+		if base > 1 {
+			next = max(next, int(math.Ceil(base*ease)))
+		}
+	} else {
+		next = int(math.Ceil(base * ease))
+	}
+	return max(next, scheduled)
+}
+
+// ElapsedDays returns the fractional days between p's last review and now.
+func ElapsedDays(p models.Problem, now time.Time) float64 {
+	return math.Max(now.Sub(p.LastReviewed).Hours()/24, 0)
+}
+
+// intervalModifier shortens or lengthens intervals to reach the target
+// retention, assuming standard SM-2 intervals target 90% recall.
+func (s SM2) intervalModifier() float64 {
+	if s.TargetRetention <= 0 || s.TargetRetention >= 1 {
+		return 1
+	}
+	return math.Log(s.TargetRetention) / math.Log(DefaultRequestRetention)
+}
+
+// Retrievability assumes each SM-2 interval is chosen so that recall has
+// dropped to the target retention (90% by default) by the time the problem
+// is due, with exponential decay.
+func (s SM2) Retrievability(p models.Problem, now time.Time) float64 {
+	if p.Interval <= 0 {
+		return 1
+	}
+	target := s.TargetRetention
+	if target <= 0 || target >= 1 {
+		target = DefaultRequestRetention
+	}
+	return math.Pow(target, ElapsedDays(p, now)/float64(p.Interval))
+}
+
+func (s SM2) Params() []float64 {
+	return append([]float64(nil), s.EaseDeltas[:]...)
+}
+
+func (SM2) ParamBounds() [][2]float64 {
+	return [][2]float64{
+		{-1, 0}, {-1, 0}, {-1, 0},
+		{-0.5, 0.2}, {-0.3, 0.3}, {-0.1, 0.5},
+	}
+}
+
+func (s SM2) WithParams(params []float64) (Scheduler, error) {
+	if len(params) != len(s.EaseDeltas) {
+		return nil, fmt.Errorf("sm2: expected %d parameters, got %d", len(s.EaseDeltas), len(params))
+	}
+	copy(s.EaseDeltas[:], params)
+	return s, nil
 }
@@ -0,0 +1,95 @@
+package algorithm_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+func TestSM2Init(t *testing.T) {
+	p := algorithm.NewSM2().Init(models.Problem{Name: "Two Sum", Lapses: 4}, now)
+	if p.EaseFactor != algorithm.InitialEaseFactor || p.Lapses != 0 || !p.LastReviewed.Equal(now) {
+		t.Errorf("got ease %v, %d lapses, last reviewed %v", p.EaseFactor, p.Lapses, p.LastReviewed)
+	}
+	checkDue(t, "Init", p, algorithm.InitialInterval)
+}
+
+func TestSM2Review(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		p        models.Problem
+		sched    algorithm.SM2
+		quality  int
+		interval int
+		ease     float64
+		lapses   int
+	}{
+		{"second repetition", learned(1, 2.5, 1), algorithm.NewSM2(), 4, 6, 2.5, 0},
+		{"graduating", learned(0, 2.5, 0), algorithm.NewSM2(), 4, 1, 2.5, 0},
+		{"on time, perfect", learned(6, 2.5, 6), algorithm.NewSM2(), 5, 16, 2.6, 0},
+		{"on time, hesitant", learned(6, 2.5, 6), algorithm.NewSM2(), 3, 15, 2.36, 0},
+		{"overdue", learned(10, 2.5, 20), algorithm.NewSM2(), 4, 50, 2.5, 0},
+		{"overdue, hesitant", learned(10, 2.5, 20), algorithm.NewSM2(), 3, 36, 2.36, 0},
+		{"overdue second repetition", learned(1, 2.5, 4), algorithm.NewSM2(), 4, 10, 2.5, 0},
+		{"early", learned(10, 2.5, 2), algorithm.NewSM2(), 4, 10, 2.5, 0},
+		{"lapse", learned(30, 2.5, 30), algorithm.NewSM2(), 1, 1, 1.96, 1},
+		{"ease floor", learned(5, 1.4, 5), algorithm.NewSM2(), 0, 1, algorithm.MinEaseFactor, 1},
+		{"configured ease floor", learned(5, 1.6, 5), algorithm.SM2{EaseDeltas: algorithm.DefaultEaseDeltas, EaseFloor: 1.5}, 0, 1, 1.5, 1},
+		{"quality above 5", learned(6, 2.5, 6), algorithm.NewSM2(), 7, 16, 2.6, 0},
+		{"higher target retention", learned(10, 2.5, 10), algorithm.SM2{EaseDeltas: algorithm.DefaultEaseDeltas, TargetRetention: 0.95}, 4, 13, 2.5, 0},
+	} {
+		p := tt.sched.Review(tt.p, tt.quality, now)
+		checkDue(t, tt.name, p, tt.interval)
+		if math.Abs(p.EaseFactor-tt.ease) > 1e-9 || p.Lapses != tt.lapses {
+			t.Errorf("%s: got ease %v and %d lapses, want %v and %d", tt.name, p.EaseFactor, p.Lapses, tt.ease, tt.lapses)
+		}
+		if !p.LastReviewed.Equal(now) {
+			t.Errorf("%s: got last reviewed %v, want %v", tt.name, p.LastReviewed, now)
+		}
+	}
+}
+
+func TestSM2Retrievability(t *testing.T) {
+	s := algorithm.NewSM2()
+	for _, tt := range []struct {
+		p    models.Problem
+		want float64
+	}{
+		{learned(10, 2.5, 0), 1},
+		{learned(10, 2.5, 10), 0.9},
+		{learned(10, 2.5, 20), 0.81},
+		{learned(0, 2.5, 5), 1},
+	} {
+		if got := s.Retrievability(tt.p, now); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("interval %d after %v: got %v, want %v", tt.p.Interval, now.Sub(tt.p.LastReviewed), got, tt.want)
+		}
+	}
+	strict := algorithm.SM2{EaseDeltas: algorithm.DefaultEaseDeltas, TargetRetention: 0.95}
+	if got := strict.Retrievability(learned(10, 2.5, 10), now); math.Abs(got-0.95) > 1e-9 {
+		t.Errorf("target retention 0.95: got %v when due, want 0.95", got)
+	}
+}
+
+func TestSM2WithParams(t *testing.T) {
+	s := algorithm.NewSM2()
+	params := s.Params()
+	params[5] = 0.2
+	next, err := s.WithParams(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := next.Review(learned(6, 2.5, 6), 5, now); math.Abs(p.EaseFactor-2.7) > 1e-9 {
+		t.Errorf("got ease %v, want 2.7", p.EaseFactor)
+	}
+	if s.Params()[5] != algorithm.DefaultEaseDeltas[5] {
+		t.Error("WithParams changed the original scheduler")
+	}
+	if _, err := s.WithParams(params[:3]); err == nil {
+		t.Error("WithParams with 3 parameters: got no error")
+	}
+	if len(s.ParamBounds()) != len(s.Params()) {
+		t.Errorf("got %d bounds for %d parameters", len(s.ParamBounds()), len(s.Params()))
+	}
+}
@@ -0,0 +1,102 @@
+package algorithm
+
+import (
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// Steps wraps a scheduler with short learning and relearning steps. New
+// problems are due immediately and repeat after each learning step before
+// graduating to the wrapped scheduler's day intervals; failed reviews go
+// through the relearning steps before returning to their new interval.
+type Steps struct {
+	Scheduler
+	Learning   []time.Duration
+	Relearning []time.Duration
+}
+
+// WithSteps wraps s with the given learning and relearning steps.
+func WithSteps(s Scheduler, learning, relearning []time.Duration) Scheduler {
+	return Steps{Scheduler: s, Learning: learning, Relearning: relearning}
+}
+
+func (s Steps) Init(p models.Problem, now time.Time) models.Problem {
+	p = s.Scheduler.Init(p, now)
+	p.State = models.StateNew
+	p.Step = 0
+	if len(s.Learning) > 0 {
+		// Leave the interval (and box) at zero so graduating gives the first day interval.
+		p.Interval = 0
+		p.Box = 0
+		p.NextReview = now
+	}
+	return p
+}
+
+func (s Steps) Review(p models.Problem, quality int, now time.Time) models.Problem {
+	switch p.State {
+	case models.StateNew, models.StateLearning:
+		if len(s.Learning) == 0 {
+			break
+		}
+		if quality < 3 {
+			return s.step(p, models.StateLearning, 0, s.Learning, now)
+		}
+		if quality < 5 && p.Step+1 < len(s.Learning) {
+			return s.step(p, models.StateLearning, p.Step+1, s.Learning, now)
+		}
+		// Graduate: the first real review starts the day intervals.
+		p.Interval = 0
+
+	case models.StateRelearning:
+		if len(s.Relearning) == 0 {
+			break
+		}
+		if quality < 3 {
+			return s.step(p, models.StateRelearning, 0, s.Relearning, now)
+		}
+		if p.Step+1 < len(s.Relearning) {
+			return s.step(p, models.StateRelearning, p.Step+1, s.Relearning, now)
+		}
+		// Graduate back to the interval computed when the lapse happened.
+		p.State = models.StateReview
+		p.Step = 0
+		p.LastReviewed = now
+		p.NextReview = now.AddDate(0, 0, p.Interval)
+		return p
+
+	default:
+		if quality < 3 && len(s.Relearning) > 0 {
+			p = s.Scheduler.Review(p, quality, now)
+			return s.step(p, models.StateRelearning, 0, s.Relearning, now)
+		}
+	}
+
+	p = s.Scheduler.Review(p, quality, now)
+	p.State = models.StateReview
+	p.Step = 0
+	return p
+}
+
+// step schedules p for the given learning step, in minutes rather than days.
+func (s Steps) step(p models.Problem, state models.State, step int, steps []time.Duration, now time.Time) models.Problem {
+	p.State = state
+	p.Step = step
+	p.LastReviewed = now
+	p.NextReview = now.Add(steps[step])
+	return p
+}
+
+func (s Steps) IntervalLimit(p models.Problem) int {
+	return intervalLimit(s.Scheduler, p)
+}
+
+func (s Steps) WithParams(params []float64) (Scheduler, error) {
+	inner, err := s.Scheduler.WithParams(params)
+	if err != nil {
+		return nil, err
+	}
+	s.Scheduler = inner
+	return s, nil
+}
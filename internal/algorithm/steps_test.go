@@ -0,0 +1,75 @@
+package algorithm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+func TestStepsLearning(t *testing.T) {
+	s := algorithm.WithSteps(algorithm.NewSM2(), []time.Duration{10 * time.Minute, time.Hour}, []time.Duration{10 * time.Minute})
+	p := s.Init(models.Problem{}, now)
+	if p.State != models.StateNew || p.Interval != 0 || !p.NextReview.Equal(now) {
+		t.Fatalf("Init: got state %s, interval %d due %v, want new, 0 due now", p.State, p.Interval, p.NextReview)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		quality int
+		after   time.Duration // since the previous review
+		state   models.State
+		step    int
+		due     time.Duration // after this review
+	}{
+		{"good first step", 4, 0, models.StateLearning, 1, time.Hour},
+		{"forgotten", 1, time.Hour, models.StateLearning, 0, 10 * time.Minute},
+		{"good again", 4, 10 * time.Minute, models.StateLearning, 1, time.Hour},
+		{"graduates", 4, time.Hour, models.StateReview, 0, 24 * time.Hour},
+	} {
+		at := p.LastReviewed.Add(tt.after)
+		p = s.Review(p, tt.quality, at)
+		if p.State != tt.state || p.Step != tt.step || !p.NextReview.Equal(at.Add(tt.due)) {
+			t.Errorf("%s: got %s step %d due %v, want %s step %d due %v", tt.name, p.State, p.Step, p.NextReview, tt.state, tt.step, at.Add(tt.due))
+		}
+	}
+
+	easy := s.Review(s.Init(models.Problem{}, now), 5, now)
+	if easy.State != models.StateReview || easy.Interval != 1 {
+		t.Errorf("easy first review: got %s with interval %d, want review with 1", easy.State, easy.Interval)
+	}
+}
+
+func TestStepsRelearning(t *testing.T) {
+	s := algorithm.WithSteps(algorithm.NewSM2(), nil, []time.Duration{10 * time.Minute, time.Hour})
+	p := s.Review(learned(30, 2.5, 30), 1, now)
+	if p.State != models.StateRelearning || p.Step != 0 || p.Lapses != 1 || p.Interval != 1 || !p.NextReview.Equal(now.Add(10*time.Minute)) {
+		t.Fatalf("lapse: got %s step %d, %d lapses, interval %d due %v", p.State, p.Step, p.Lapses, p.Interval, p.NextReview)
+	}
+	at := now.Add(10 * time.Minute)
+	p = s.Review(p, 3, at)
+	if p.State != models.StateRelearning || p.Step != 1 || !p.NextReview.Equal(at.Add(time.Hour)) {
+		t.Errorf("first relearning step: got %s step %d due %v", p.State, p.Step, p.NextReview)
+	}
+	at = at.Add(time.Hour)
+	p = s.Review(p, 4, at)
+	if p.State != models.StateReview || p.Interval != 1 || !p.NextReview.Equal(at.AddDate(0, 0, 1)) || p.Lapses != 1 {
+		t.Errorf("graduating: got %s with interval %d due %v and %d lapses, want review due in a day after 1 lapse", p.State, p.Interval, p.NextReview, p.Lapses)
+	}
+}
+
+// Without steps, reviews go straight to the wrapped scheduler.
+func TestStepsNone(t *testing.T) {
+	s := algorithm.WithSteps(algorithm.NewSM2(), nil, nil)
+	p := s.Init(models.Problem{}, now)
+	if p.State != models.StateNew {
+		t.Errorf("Init: got state %s, want new", p.State)
+	}
+	checkDue(t, "Init", p, 1)
+	p = s.Review(learned(30, 2.5, 30), 1, now)
+	if p.State != models.StateReview || p.Lapses != 1 {
+		t.Errorf("lapse: got %s with %d lapses, want review with 1", p.State, p.Lapses)
+	}
+	checkDue(t, "lapse", p, 1)
+}
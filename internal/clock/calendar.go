@@ -0,0 +1,48 @@
+package clock
+
+import "time"
+
+// Calendar decides which study day a moment belongs to. A study day starts
+// at DayStartHour in Location rather than at midnight, so late-night
+// reviews still count towards the day they were started on.
+type Calendar struct {
+	Location     *time.Location
+	DayStartHour int
+}
+
+func (c Calendar) location() *time.Location {
+	if c.Location == nil {
+		return time.Local
+	}
+	return c.Location
+}
+
+// DayStart returns the moment the study day containing t began.
+func (c Calendar) DayStart(t time.Time) time.Time {
+	loc := c.location()
+	shifted := t.In(loc).Add(-time.Duration(c.DayStartHour) * time.Hour)
+	y, m, d := shifted.Date()
+	return time.Date(y, m, d, c.DayStartHour, 0, 0, 0, loc)
+}
+
+// DayEnd returns the moment the study day containing t ends, i.e. the start
+// of the next one. Anything due before DayEnd(now) is due today.
+func (c Calendar) DayEnd(t time.Time) time.Time {
+	return c.DayStart(t).AddDate(0, 0, 1)
+}
+
+// Date formats the study day containing t as YYYY-MM-DD.
+func (c Calendar) Date(t time.Time) string {
+	return c.DayStart(t).Format("2006-01-02")
+}
+
+// DaysBetween returns the number of study days from the day containing a
+// to the day containing b.
+func (c Calendar) DaysBetween(a, b time.Time) int {
+	ya, ma, da := c.DayStart(a).Date()
+	yb, mb, db := c.DayStart(b).Date()
+	// Compare calendar dates in UTC so DST changes don't skew the count.
+	ua := time.Date(ya, ma, da, 0, 0, 0, 0, time.UTC)
+	ub := time.Date(yb, mb, db, 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
@@ -0,0 +1,108 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+)
+
+// location loads an IANA timezone, failing the test if it is unknown.
+func location(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestCalendarDayStart(t *testing.T) {
+	berlin := location(t, "Europe/Berlin")
+	for _, tt := range []struct {
+		name       string
+		cal        clock.Calendar
+		at         time.Time
+		start, end time.Time
+	}{
+		{"midnight rollover", clock.Calendar{Location: time.UTC},
+			time.Date(2026, 3, 10, 2, 30, 0, 0, time.UTC),
+			time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"before the rollover hour", clock.Calendar{Location: time.UTC, DayStartHour: 4},
+			time.Date(2026, 3, 10, 3, 59, 0, 0, time.UTC),
+			time.Date(2026, 3, 9, 4, 0, 0, 0, time.UTC), time.Date(2026, 3, 10, 4, 0, 0, 0, time.UTC)},
+		{"at the rollover hour", clock.Calendar{Location: time.UTC, DayStartHour: 4},
+			time.Date(2026, 3, 10, 4, 0, 0, 0, time.UTC),
+			time.Date(2026, 3, 10, 4, 0, 0, 0, time.UTC), time.Date(2026, 3, 11, 4, 0, 0, 0, time.UTC)},
+		{"other timezone", clock.Calendar{Location: berlin, DayStartHour: 4},
+			time.Date(2026, 3, 10, 2, 30, 0, 0, time.UTC), // 03:30 in Berlin
+			time.Date(2026, 3, 9, 4, 0, 0, 0, berlin), time.Date(2026, 3, 10, 4, 0, 0, 0, berlin)},
+		{"day losing an hour", clock.Calendar{Location: berlin},
+			time.Date(2026, 3, 29, 12, 0, 0, 0, berlin),
+			time.Date(2026, 3, 29, 0, 0, 0, 0, berlin), time.Date(2026, 3, 30, 0, 0, 0, 0, berlin)},
+		{"day gaining an hour", clock.Calendar{Location: berlin, DayStartHour: 4},
+			time.Date(2026, 10, 26, 1, 0, 0, 0, berlin),
+			time.Date(2026, 10, 25, 4, 0, 0, 0, berlin), time.Date(2026, 10, 26, 4, 0, 0, 0, berlin)},
+	} {
+		if got := tt.cal.DayStart(tt.at); !got.Equal(tt.start) {
+			t.Errorf("%s: DayStart got %v, want %v", tt.name, got, tt.start)
+		}
+		if got := tt.cal.DayEnd(tt.at); !got.Equal(tt.end) {
+			t.Errorf("%s: DayEnd got %v, want %v", tt.name, got, tt.end)
+		}
+	}
+
+	// Study days keep their wall clock hours, so their length changes with DST.
+	cal := clock.Calendar{Location: berlin}
+	for _, tt := range []struct {
+		at   time.Time
+		want time.Duration
+	}{
+		{time.Date(2026, 3, 29, 12, 0, 0, 0, berlin), 23 * time.Hour},
+		{time.Date(2026, 6, 1, 12, 0, 0, 0, berlin), 24 * time.Hour},
+		{time.Date(2026, 10, 25, 12, 0, 0, 0, berlin), 25 * time.Hour},
+	} {
+		if got := cal.DayEnd(tt.at).Sub(cal.DayStart(tt.at)); got != tt.want {
+			t.Errorf("length of the day of %v: got %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestCalendarDate(t *testing.T) {
+	at := time.Date(2026, 3, 10, 1, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name string
+		cal  clock.Calendar
+		want string
+	}{
+		{"UTC", clock.Calendar{Location: time.UTC}, "2026-03-10"},
+		{"UTC rolling over at 4", clock.Calendar{Location: time.UTC, DayStartHour: 4}, "2026-03-09"},
+		{"Tokyo", clock.Calendar{Location: location(t, "Asia/Tokyo"), DayStartHour: 4}, "2026-03-10"},
+		{"New York", clock.Calendar{Location: location(t, "America/New_York")}, "2026-03-09"},
+	} {
+		if got := tt.cal.Date(at); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCalendarDaysBetween(t *testing.T) {
+	berlin := location(t, "Europe/Berlin")
+	cal := clock.Calendar{Location: berlin, DayStartHour: 4}
+	for _, tt := range []struct {
+		name string
+		a, b time.Time
+		want int
+	}{
+		{"same day", time.Date(2026, 3, 10, 5, 0, 0, 0, berlin), time.Date(2026, 3, 11, 3, 0, 0, 0, berlin), 0},
+		{"across the rollover", time.Date(2026, 3, 10, 3, 0, 0, 0, berlin), time.Date(2026, 3, 10, 5, 0, 0, 0, berlin), 1},
+		{"backwards", time.Date(2026, 3, 12, 12, 0, 0, 0, berlin), time.Date(2026, 3, 10, 12, 0, 0, 0, berlin), -2},
+		{"into summer time", time.Date(2026, 3, 28, 12, 0, 0, 0, berlin), time.Date(2026, 3, 30, 12, 0, 0, 0, berlin), 2},
+		{"into winter time", time.Date(2026, 10, 24, 12, 0, 0, 0, berlin), time.Date(2026, 10, 26, 12, 0, 0, 0, berlin), 2},
+		{"over summer", time.Date(2026, 3, 1, 12, 0, 0, 0, berlin), time.Date(2026, 11, 1, 12, 0, 0, 0, berlin), 245},
+	} {
+		if got := cal.DaysBetween(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
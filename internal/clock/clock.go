@@ -0,0 +1,52 @@
+package clock
+
+import (
+	"fmt"
+	"time"
+)
+
+// Clock is the single source of "now" for scheduling and due-date queries.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// System returns a clock that reads the wall clock.
+func System() Clock { return systemClock{} }
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+// Fixed returns a clock that is frozen at t.
+func Fixed(t time.Time) Clock { return fixedClock(t) }
+
+// Layouts accepted by Parse, from most to least specific.
+var layouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Parse reads a timestamp such as "2024-05-01" or "2024-05-01 09:30" in the
+// calendar's timezone, or an RFC 3339 timestamp with an explicit offset. A
+// date alone means the start of that study day, so it falls on that day
+// even when days roll over after midnight.
+func Parse(s string, cal Calendar) (time.Time, error) {
+	loc := cal.location()
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err != nil {
+			continue
+		}
+		if layout == "2006-01-02" {
+			t = time.Date(t.Year(), t.Month(), t.Day(), cal.DayStartHour, 0, 0, 0, loc)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (expected YYYY-MM-DD, YYYY-MM-DD HH:MM or RFC 3339)", s)
+}
@@ -0,0 +1,50 @@
+package clock_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+)
+
+func TestFixed(t *testing.T) {
+	at := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	if got := clock.Fixed(at).Now(); !got.Equal(at) {
+		t.Errorf("got %v, want %v", got, at)
+	}
+	before := time.Now()
+	if got := clock.System().Now(); got.Before(before) || got.After(time.Now()) {
+		t.Errorf("System: got %v, want the current time", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tokyo := location(t, "Asia/Tokyo")
+	cal := clock.Calendar{Location: tokyo, DayStartHour: 4}
+	for _, tt := range []struct {
+		in   string
+		want time.Time
+	}{
+		{"2026-03-10", time.Date(2026, 3, 10, 4, 0, 0, 0, tokyo)},
+		{"2026-03-10 09:30", time.Date(2026, 3, 10, 9, 30, 0, 0, tokyo)},
+		{"2026-03-10T09:30", time.Date(2026, 3, 10, 9, 30, 0, 0, tokyo)},
+		{"2026-03-10T09:30:00Z", time.Date(2026, 3, 10, 9, 30, 0, 0, time.UTC)},
+		{"2026-03-10T09:30:00+01:00", time.Date(2026, 3, 10, 8, 30, 0, 0, time.UTC)},
+	} {
+		got, err := clock.Parse(tt.in, cal)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("Parse(%q): got %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+	// A date alone falls on its own study day, not the one before.
+	if got, _ := clock.Parse("2026-03-10", cal); cal.Date(got) != "2026-03-10" {
+		t.Errorf(`Parse("2026-03-10"): got study day %s`, cal.Date(got))
+	}
+
+	for _, in := range []string{"", "tomorrow", "2026-13-01", "10/03/2026", "2026-03-10 25:00"} {
+		if _, err := clock.Parse(in, cal); err == nil || !strings.Contains(err.Error(), "invalid time") {
+			t.Errorf("Parse(%q): got error %v, want an invalid time", in, err)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/algorithm"
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// Config holds the per-database user settings. Values are persisted as
+// strings in the settings table under each Option's key.
+type Config struct {
+	Timezone     string // IANA timezone name, empty for the system timezone
+	DayStartHour int    // Hour (0-23) at which a new study day begins
+
+	MaxInterval     int     // Longest interval in days (0 for no limit)
+	EaseFloor       float64 // Lowest SM-2 ease
+	TargetRetention float64 // Recall probability to schedule reviews at
+
+	InterviewDate    time.Time // Date to prepare for (zero when unset), as a UTC calendar date
+	InterviewReviews int       // Reviews each problem should get before InterviewDate
+
+	LearningSteps   []time.Duration // Steps for new problems before they graduate
+	RelearningSteps []time.Duration // Steps for failed problems before they return to review
+
+	LeechThreshold int    // Lapses after which a problem is a leech (0 disables)
+	LeechAction    string // What to do with a new leech: "tag" or "suspend"
+
+	IntervalFuzz bool // Randomly spread new intervals over a small window
+	LoadBalance  bool // Prefer lighter days within the fuzz window
+
+	LeitnerIntervals []int // Interval in days of each Leitner box
+
+	DifficultyEase          [5]float64 // Initial ease for difficulty 1..5
+	DifficultyFirstInterval [5]int     // First interval in days for difficulty 1..5
+}
+
+// Option describes one user-facing setting.
+type Option struct {
+	Key         string
+	Description string
+	Default     string
+	apply       func(c *Config, value string) error
+}
+
+// Options lists every setting that can be changed with 'recall config'.
+var Options = []Option{
+	{
+		Key:         "timezone",
+		Description: "IANA timezone used to decide what \"today\" is (empty for system timezone)",
+		Default:     "",
+		apply: func(c *Config, value string) error {
+			if _, err := time.LoadLocation(value); err != nil {
+				return fmt.Errorf("unknown timezone %q", value)
+			}
+			c.Timezone = value
+			return nil
+		},
+	},
+	{
+		Key:         "day_start_hour",
+		Description: "Hour (0-23) at which a new study day begins",
+		Default:     "4",
+		apply: func(c *Config, value string) error {
+			hour, err := strconv.Atoi(value)
+			if err != nil || hour < 0 || hour > 23 {
+				return fmt.Errorf("day_start_hour must be an hour between 0 and 23")
+			}
+			c.DayStartHour = hour
+			return nil
+		},
+	},
+	{
+		Key:         "max_interval",
+		Description: "Longest interval in days a problem can be scheduled out (0 for no limit)",
+		Default:     "0",
+		apply: func(c *Config, value string) error {
+			days, err := strconv.Atoi(value)
+			if err != nil || days < 0 {
+				return fmt.Errorf("max_interval must be a non-negative number of days")
+			}
+			c.MaxInterval = days
+			return nil
+		},
+	},
+	{
+		Key:         "ease_floor",
+		Description: "Lowest ease an SM-2 problem can drop to",
+		Default:     "1.3",
+		apply: func(c *Config, value string) error {
+			floor, err := strconv.ParseFloat(value, 64)
+			if err != nil || floor < 1 {
+				return fmt.Errorf("ease_floor must be a number of at least 1")
+			}
+			c.EaseFloor = floor
+			return nil
+		},
+	},
+	{
+		Key:         "target_retention",
+		Description: "Recall probability to schedule reviews at, e.g. 0.9 (higher means shorter intervals)",
+		Default:     "0.9",
+		apply: func(c *Config, value string) error {
+			r, err := ParseRetention(value)
+			c.TargetRetention = r
+			return err
+		},
+	},
+	{
+		Key:         "interview_date",
+		Description: "Date (YYYY-MM-DD) to prepare for: intervals shrink so every problem is reviewed before it",
+		Default:     "",
+		apply: func(c *Config, value string) error {
+			if value == "" {
+				c.InterviewDate = time.Time{}
+				return nil
+			}
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				return fmt.Errorf("interview_date must be a date like 2026-12-01")
+			}
+			c.InterviewDate = date
+			return nil
+		},
+	},
+	{
+		Key:         "interview_reviews",
+		Description: "Reviews every problem should get before interview_date",
+		Default:     "3",
+		apply: func(c *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return fmt.Errorf("interview_reviews must be at least 1")
+			}
+			c.InterviewReviews = n
+			return nil
+		},
+	},
+	{
+		Key:         "learning_steps",
+		Description: "Comma-separated steps for new problems, e.g. 10m,1h (none to disable)",
+		Default:     "none",
+		apply: func(c *Config, value string) (err error) {
+			c.LearningSteps, err = ParseSteps(value)
+			return err
+		},
+	},
+	{
+		Key:         "relearning_steps",
+		Description: "Comma-separated steps after a failed review, e.g. 10m (none to disable)",
+		Default:     "none",
+		apply: func(c *Config, value string) (err error) {
+			c.RelearningSteps, err = ParseSteps(value)
+			return err
+		},
+	},
+	{
+		Key:         "leech_threshold",
+		Description: "Lapses after which a problem is flagged as a leech (0 disables)",
+		Default:     "8",
+		apply: func(c *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("leech_threshold must be a non-negative number")
+			}
+			c.LeechThreshold = n
+			return nil
+		},
+	},
+	{
+		Key:         "leech_action",
+		Description: "What to do with a new leech: tag or suspend (suspend also tags)",
+		Default:     "tag",
+		apply: func(c *Config, value string) error {
+			if value != "tag" && value != "suspend" {
+				return fmt.Errorf("leech_action must be tag or suspend")
+			}
+			c.LeechAction = value
+			return nil
+		},
+	},
+	{
+		Key:         "interval_fuzz",
+		Description: "Randomly spread new intervals over a few days (true/false)",
+		Default:     "false",
+		apply: func(c *Config, value string) (err error) {
+			c.IntervalFuzz, err = parseBool("interval_fuzz", value)
+			return err
+		},
+	},
+	{
+		Key:         "load_balance",
+		Description: "Move fuzzed due dates towards days with fewer reviews (true/false)",
+		Default:     "false",
+		apply: func(c *Config, value string) (err error) {
+			c.LoadBalance, err = parseBool("load_balance", value)
+			return err
+		},
+	},
+	{
+		Key:         "leitner_intervals",
+		Description: "Comma-separated interval in days of each Leitner box, e.g. 1,2,4,8,16,32",
+		Default:     "1,2,4,8,16,32",
+		apply: func(c *Config, value string) error {
+			var intervals []int
+			for _, part := range strings.Split(value, ",") {
+				days, err := strconv.Atoi(strings.TrimSpace(part))
+				if err != nil || days < 1 {
+					return fmt.Errorf("invalid box interval %q (use whole days)", part)
+				}
+				if len(intervals) > 0 && days <= intervals[len(intervals)-1] {
+					return fmt.Errorf("box intervals must be increasing")
+				}
+				intervals = append(intervals, days)
+			}
+			c.LeitnerIntervals = intervals
+			return nil
+		},
+	},
+	{
+		Key:         "difficulty_ease",
+		Description: "Initial SM-2 ease for difficulty 1,2,3,4,5",
+		Default:     "2.8,2.65,2.5,2.3,2.1",
+		apply: func(c *Config, value string) error {
+			parts := strings.Split(value, ",")
+			if len(parts) != 5 {
+				return fmt.Errorf("difficulty_ease needs 5 comma-separated values")
+			}
+			for i, part := range parts {
+				ease, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+				if err != nil || ease < 1.3 {
+					return fmt.Errorf("invalid ease %q (must be at least 1.3)", part)
+				}
+				c.DifficultyEase[i] = ease
+			}
+			return nil
+		},
+	},
+	{
+		Key:         "difficulty_first_interval",
+		Description: "First SM-2 interval in days for difficulty 1,2,3,4,5",
+		Default:     "3,2,1,1,1",
+		apply: func(c *Config, value string) error {
+			parts := strings.Split(value, ",")
+			if len(parts) != 5 {
+				return fmt.Errorf("difficulty_first_interval needs 5 comma-separated values")
+			}
+			for i, part := range parts {
+				days, err := strconv.Atoi(strings.TrimSpace(part))
+				if err != nil || days < 1 {
+					return fmt.Errorf("invalid interval %q (use whole days)", part)
+				}
+				c.DifficultyFirstInterval[i] = days
+			}
+			return nil
+		},
+	},
+}
+
+func parseBool(key, value string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", key)
+	}
+	return b, nil
+}
+
+// IsLeech reports whether a problem with the given lapse count is a leech.
+func (c Config) IsLeech(lapses int) bool {
+	return c.LeechThreshold > 0 && lapses >= c.LeechThreshold
+}
+
+// ParseRetention reads a target recall probability between 0.5 and 0.99.
+func ParseRetention(value string) (float64, error) {
+	r, err := strconv.ParseFloat(value, 64)
+	if err != nil || r < 0.5 || r > 0.99 {
+		return 0, fmt.Errorf("target retention must be between 0.5 and 0.99")
+	}
+	return r, nil
+}
+
+// ParseSteps reads a list of durations such as "10m,1h", or "none".
+func ParseSteps(value string) ([]time.Duration, error) {
+	if strings.TrimSpace(value) == "none" {
+		return nil, nil
+	}
+	var steps []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid step %q (use durations like 10m or 1h)", part)
+		}
+		steps = append(steps, d)
+	}
+	return steps, nil
+}
+
+// Lookup returns the option registered under key.
+func Lookup(key string) (Option, bool) {
+	for _, o := range Options {
+		if o.Key == key {
+			return o, true
+		}
+	}
+	return Option{}, false
+}
+
+// Validate reports whether value is acceptable for the option.
+func (o Option) Validate(value string) error {
+	var c Config
+	return o.apply(&c, value)
+}
+
+// Load builds a Config from stored settings, falling back to each option's
+// default when a setting is unset. get returns "" for unset keys.
+func Load(get func(key string) (string, error)) (Config, error) {
+	var c Config
+	for _, o := range Options {
+		value, err := get(o.Key)
+		if err != nil {
+			return c, err
+		}
+		if value == "" {
+			value = o.Default
+		}
+		if err := o.apply(&c, value); err != nil {
+			return c, fmt.Errorf("setting %s: %w", o.Key, err)
+		}
+	}
+	return c, nil
+}
+
+// SchedulerOptions returns the default scheduler options, with the values
+// set in preset taking precedence when preset is not nil.
+func (c Config) SchedulerOptions(preset *models.Preset) (algorithm.Options, error) {
+	opts := algorithm.Options{
+		MaxInterval:             c.MaxInterval,
+		Deadline:                c.Deadline(),
+		DeadlineReviews:         c.InterviewReviews,
+		EaseFloor:               c.EaseFloor,
+		TargetRetention:         c.TargetRetention,
+		LearningSteps:           c.LearningSteps,
+		RelearningSteps:         c.RelearningSteps,
+		LeitnerIntervals:        c.LeitnerIntervals,
+		DifficultyEase:          c.DifficultyEase,
+		DifficultyFirstInterval: c.DifficultyFirstInterval,
+	}
+	if preset == nil {
+		return opts, nil
+	}
+
+	if preset.MaxInterval > 0 {
+		opts.MaxInterval = preset.MaxInterval
+	}
+	if preset.EaseFloor > 0 {
+		opts.EaseFloor = preset.EaseFloor
+	}
+	if preset.TargetRetention > 0 {
+		opts.TargetRetention = preset.TargetRetention
+	}
+	var err error
+	if preset.LearningSteps != "" {
+		if opts.LearningSteps, err = ParseSteps(preset.LearningSteps); err != nil {
+			return opts, fmt.Errorf("preset %s: %w", preset.Name, err)
+		}
+	}
+	if preset.RelearningSteps != "" {
+		if opts.RelearningSteps, err = ParseSteps(preset.RelearningSteps); err != nil {
+			return opts, fmt.Errorf("preset %s: %w", preset.Name, err)
+		}
+	}
+	return opts, nil
+}
+
+// Deadline returns the start of the interview_date study day, or the zero
+// time when no interview date is set.
+func (c Config) Deadline() time.Time {
+	if c.InterviewDate.IsZero() {
+		return time.Time{}
+	}
+	cal := c.Calendar()
+	y, m, d := c.InterviewDate.Date()
+	return time.Date(y, m, d, cal.DayStartHour, 0, 0, 0, cal.Location)
+}
+
+// Calendar returns the study-day calendar described by the config.
+func (c Config) Calendar() clock.Calendar {
+	loc := time.Local
+	if c.Timezone != "" {
+		if l, err := time.LoadLocation(c.Timezone); err == nil {
+			loc = l
+		}
+	}
+	return clock.Calendar{Location: loc, DayStartHour: c.DayStartHour}
+}
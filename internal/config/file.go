@@ -0,0 +1,86 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+)
+
+// File holds the global settings read from ~/.recall/config. Unlike the
+// per-database options, they are needed before a database is opened.
+//
+// The file has one "key = value" setting per line; lines starting with #
+// are comments.
+type File struct {
+	DB         string // Database file to use instead of a collection
+	Collection string // Collection to use when none is given
+}
+
+// FilePath returns the location of the global config file.
+func FilePath() (string, error) {
+	dir, err := db.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config"), nil
+}
+
+// LoadFile reads the global config file at path. A missing file yields
+// empty settings.
+func LoadFile(path string) (File, error) {
+	var f File
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return f, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return f, fmt.Errorf("%s:%d: expected key = value", path, line)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch key {
+		case "db":
+			f.DB, err = expandHome(value)
+			if err != nil {
+				return f, err
+			}
+		case "collection":
+			if err := db.ValidateCollection(value); err != nil {
+				return f, fmt.Errorf("%s:%d: %w", path, line, err)
+			}
+			f.Collection = value
+		default:
+			return f, fmt.Errorf("%s:%d: unknown setting %q (use db or collection)", path, line, key)
+		}
+	}
+	return f, scanner.Err()
+}
+
+// expandHome replaces a leading ~/ with the home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[2:]), nil
+}
@@ -0,0 +1,216 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/LavenderBridge/spaced-repetition/internal/query"
+)
+
+// The benchmarks share one database the size of a heavy user's: 50k
+// problems with 500k reviews, spread over the months around benchNow.
+const (
+	benchProblems        = 50_000
+	benchReviewsEach     = 10
+	benchTags            = 20
+	benchTagsPerProblem  = 2
+	benchDaysScheduled   = 90 // next reviews fall within 30 days back and 60 ahead
+	benchDaysOverdueFrom = 30
+)
+
+var benchNow = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+var (
+	benchOnce sync.Once
+	benchPath string
+	benchErr  error
+)
+
+// TestMain removes the benchmark database, which outlives any single
+// benchmark's temporary directory.
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if benchPath != "" {
+		os.RemoveAll(filepath.Dir(benchPath))
+	}
+	os.Exit(code)
+}
+
+// benchStore opens the shared benchmark database, creating it on first use.
+func benchStore(b *testing.B) *Store {
+	b.Helper()
+	benchOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "recall-bench")
+		if err != nil {
+			benchErr = err
+			return
+		}
+		benchPath = filepath.Join(dir, "recall.db")
+		benchErr = fillBenchStore(benchPath)
+	})
+	if benchErr != nil {
+		b.Fatal(benchErr)
+	}
+	s, err := NewStore(benchPath)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { s.Close() })
+	s.SetClock(clock.Fixed(benchNow))
+	s.SetCalendar(clock.Calendar{Location: time.UTC, DayStartHour: 4})
+	return s
+}
+
+func fillBenchStore(path string) error {
+	s, err := NewStore(path)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return s.inTx(func(tx *sql.Tx) error {
+		for i := 1; i <= benchProblems; i++ {
+			next := benchNow.AddDate(0, 0, i%benchDaysScheduled-benchDaysOverdueFrom)
+			p := models.Problem{
+				Name:         fmt.Sprintf("problem %d", i),
+				Notes:        fmt.Sprintf("notes for problem %d", i),
+				Difficulty:   1 + i%5,
+				Interval:     1 + i%60,
+				EaseFactor:   1.3 + float64(i%20)/10,
+				LastReviewed: next.AddDate(0, 0, -(1 + i%60)),
+				NextReview:   next,
+				State:        models.StateReview,
+				Lapses:       i % 7,
+			}
+			for t := range benchTagsPerProblem {
+				p.Tags = append(p.Tags, models.Tag{Name: fmt.Sprint("tag", (i+t*7)%benchTags)})
+			}
+			id, err := addProblem(tx, p)
+			if err != nil {
+				return err
+			}
+			for r := range benchReviewsEach {
+				review := models.Review{
+					ProblemID:  id,
+					Quality:    r % 6,
+					ReviewedAt: p.LastReviewed.AddDate(0, 0, -10*(benchReviewsEach-r)),
+					Interval:   r + 1,
+					EaseFactor: 2.5,
+				}
+				if err := addReview(tx, review); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func BenchmarkListProblems(b *testing.B) {
+	s := benchStore(b)
+	b.ResetTimer()
+	for range b.N {
+		if _, err := s.ListProblems(false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListDue(b *testing.B) {
+	s := benchStore(b)
+	b.ResetTimer()
+	for range b.N {
+		if _, err := s.ListProblems(true); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkQueryProblems(b *testing.B) {
+	s := benchStore(b)
+	filter, err := query.Parse("tag:tag3 diff:>=4 due:<7d")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for range b.N {
+		if _, err := s.QueryProblems(query.Query{Filter: filter, Limit: 20}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCountDueOn counts the load of each day in a fuzz window, as a
+// fuzzed review does.
+func BenchmarkCountDueOn(b *testing.B) {
+	s := benchStore(b)
+	b.ResetTimer()
+	for range b.N {
+		for day := range 7 {
+			if _, err := s.CountDueOn(benchNow.AddDate(0, 0, 20+day)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkGetProblemByID(b *testing.B) {
+	s := benchStore(b)
+	b.ResetTimer()
+	for i := range b.N {
+		if _, err := s.GetProblemByID(1 + i%benchProblems); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFindProblems(b *testing.B) {
+	s := benchStore(b)
+	b.ResetTimer()
+	for range b.N {
+		if _, err := s.FindProblems("problem 4999"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListReviews(b *testing.B) {
+	s := benchStore(b)
+	b.ResetTimer()
+	for range b.N {
+		if _, err := s.ListReviews(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetReviewStats(b *testing.B) {
+	s := benchStore(b)
+	b.ResetTimer()
+	for range b.N {
+		if _, err := s.GetReviewStats(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRecordReview(b *testing.B) {
+	s := benchStore(b)
+	p, err := s.GetProblemByID(1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := range b.N {
+		p.Interval = 1 + i%60
+		if err := s.RecordReview(*p, models.Review{ProblemID: p.ID, Quality: 4, ReviewedAt: benchNow}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// busyTimeout is how long a statement waits for another recall process to
+// release the database before failing with "database is locked".
+const busyTimeout = 5 * time.Second
+
+// busyRetries is how many times a write still failing after busyTimeout is
+// attempted in total.
+const busyRetries = 4
+
+// dsn returns the data source name for the database at path. SQLite applies
+// these settings per connection, and database/sql may open several:
+//   - a busy timeout, so concurrent processes wait for each other
+//   - WAL journaling, so readers and a writer don't block one another
+//   - foreign keys, so ON DELETE CASCADE takes effect
+//   - immediate transactions, which take the write lock when they begin
+//     instead of failing when a reading transaction starts to write
+func dsn(path string) string {
+	params := url.Values{}
+	params.Set("_busy_timeout", fmt.Sprint(busyTimeout.Milliseconds()))
+	params.Set("_journal_mode", "WAL")
+	params.Set("_foreign_keys", "on")
+	params.Set("_txlock", "immediate")
+	return path + "?" + params.Encode()
+}
+
+// isBusy reports whether err means another connection holds a lock.
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked)
+}
+
+// retryBusy runs fn, running it again after a growing pause while it fails
+// because the database is busy. fn must be safe to repeat, e.g. a whole
+// transaction that was rolled back.
+func retryBusy(fn func() error) error {
+	delay := 100 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if !isBusy(err) || attempt == busyRetries {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// missingFTS5 explains the error a build without FTS5 gets when a write
+// fires search index triggers, which a build with it created after this one
+// suspended the index on opening the database.
+func missingFTS5(err error) error {
+	if err != nil && strings.Contains(err.Error(), "no such module: fts5") {
+		return fmt.Errorf("this database has a search index that needs FTS5: %w", ErrSearchUnavailable)
+	}
+	return err
+}
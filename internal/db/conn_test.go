@@ -0,0 +1,88 @@
+package db_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// TestConcurrentWriters runs several stores on one database file, as
+// separate recall processes would, each adding and reviewing problems.
+func TestConcurrentWriters(t *testing.T) {
+	const writers, problemsEach, reviewsEach = 6, 20, 3
+	path := filepath.Join(t.TempDir(), "recall.db")
+
+	stores := make([]*db.Store, writers)
+	for i := range stores {
+		store, err := db.NewStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+		stores[i] = store
+	}
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	errs := make(chan error, writers*problemsEach*(reviewsEach+2))
+	var wg sync.WaitGroup
+	for w, store := range stores {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range problemsEach {
+				name := fmt.Sprintf("writer %d problem %d", w, i)
+				p := models.Problem{Name: name, Difficulty: 3, Interval: 1, EaseFactor: 2.5, LastReviewed: now, NextReview: now,
+					Tags: []models.Tag{{Name: "shared"}, {Name: fmt.Sprint("writer-", w)}}}
+				id, err := store.AddProblem(p)
+				if err != nil {
+					errs <- fmt.Errorf("AddProblem(%s): %w", name, err)
+					continue
+				}
+				p.ID = id
+				for r := range reviewsEach {
+					p.Interval++
+					review := models.Review{ProblemID: id, Quality: 4, ReviewedAt: now.Add(time.Duration(r) * time.Hour)}
+					if err := store.RecordReview(p, review); err != nil {
+						errs <- fmt.Errorf("RecordReview(%s): %w", name, err)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if strings.Contains(err.Error(), "database is locked") {
+			t.Errorf("writers blocked each other: %v", err)
+		} else {
+			t.Error(err)
+		}
+	}
+
+	problems, err := stores[0].ListProblems(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != writers*problemsEach {
+		t.Errorf("got %d problems, want %d", len(problems), writers*problemsEach)
+	}
+	for _, p := range problems {
+		if p.Interval != 1+reviewsEach || len(p.Tags) != 2 {
+			t.Errorf("%s: got interval %d and tags %v, want %d and 2 tags", p.Name, p.Interval, p.Tags, 1+reviewsEach)
+		}
+	}
+	reviews, err := stores[0].ListReviews()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reviews) != writers*problemsEach*reviewsEach {
+		t.Errorf("got %d reviews, want %d", len(reviews), writers*problemsEach*reviewsEach)
+	}
+}
@@ -2,269 +2,400 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
 	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/LavenderBridge/spaced-repetition/internal/query"
 	_ "github.com/mattn/go-sqlite3"
 )
 
 type Store struct {
-	db *sql.DB
+	db       *sql.DB
+	clock    clock.Clock
+	calendar clock.Calendar
 }
 
-func NewStore() (*Store, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("cannot determine home directory: %w", err)
-	}
-
-	dir := filepath.Join(home, ".recall")
-	if err := os.MkdirAll(dir, 0755); err != nil {
+// NewStore opens the database at path, creating it and its directory if
+// needed, and applies any pending schema migrations.
+func NewStore(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return nil, fmt.Errorf("cannot create data directory: %w", err)
 	}
 
-	dbPath := filepath.Join(dir, "recall.db")
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sql.Open("sqlite3", dsn(path))
 	if err != nil {
 		return nil, err
 	}
 
-	if err := initSchema(db); err != nil {
+	if _, err := migrate(db); err != nil {
 		db.Close()
 		return nil, err
 	}
 
-	return &Store{db: db}, nil
+	return &Store{db: db, clock: clock.System()}, nil
 }
 
 func (s *Store) Close() error {
 	return s.db.Close()
 }
 
-func initSchema(db *sql.DB) error {
-	// Problems table
-	// We use IF NOT EXISTS. For migration, we might need manual ALTER if columns missing.
-	// For this MVP refactor, we'll just try to add columns if they don't exist logic is a bit complex for simple SQL.
-	// We'll define the ideal schema. If table exists but old schema, it might fail.
-	// Let's rely on adding columns gracefully or just CREATE IF NOT EXISTS for new tables.
-	// Since we are refactoring, let's assume we can try to ALTER TABLE for new columns.
-
-	query := `
-	CREATE TABLE IF NOT EXISTS problems (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT UNIQUE NOT NULL,
-		url TEXT,
-		notes TEXT,
-		difficulty INTEGER NOT NULL,
-		interval INTEGER DEFAULT 1,
-		ease_factor REAL DEFAULT 2.5,
-		last_reviewed DATE NOT NULL,
-		next_review DATE NOT NULL
-	);
-	`
-	if _, err := db.Exec(query); err != nil {
-		return err
-	}
+// SetClock replaces the clock used to decide what is due "now".
+func (s *Store) SetClock(c clock.Clock) {
+	s.clock = c
+}
 
-	// Tags table
-	queryTags := `
-	CREATE TABLE IF NOT EXISTS tags (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT UNIQUE NOT NULL
-	);
-	`
-	if _, err := db.Exec(queryTags); err != nil {
-		return err
-	}
+// SetCalendar replaces the calendar used to decide what "today" is.
+func (s *Store) SetCalendar(c clock.Calendar) {
+	s.calendar = c
+}
 
-	// Join table
-	queryProblemTags := `
-	CREATE TABLE IF NOT EXISTS problem_tags (
-		problem_id INTEGER,
-		tag_id INTEGER,
-		PRIMARY KEY (problem_id, tag_id),
-		FOREIGN KEY (problem_id) REFERENCES problems(id) ON DELETE CASCADE,
-		FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
-	);
-	`
-	if _, err := db.Exec(queryProblemTags); err != nil {
-		return err
-	}
+// Calendar returns the calendar used to decide what "today" is.
+func (s *Store) Calendar() clock.Calendar {
+	return s.calendar
+}
 
-	// Migrations (Simple check and Apply)
-	// Check if 'url' exists in 'problems'
-	if !columnExists(db, "problems", "url") {
-		db.Exec("ALTER TABLE problems ADD COLUMN url TEXT")
-		db.Exec("ALTER TABLE problems ADD COLUMN notes TEXT")
-		db.Exec("ALTER TABLE problems ADD COLUMN interval INTEGER DEFAULT 1")
-		db.Exec("ALTER TABLE problems ADD COLUMN ease_factor REAL DEFAULT 2.5")
-	}
-
-	// Reviews table
-	queryReviews := `
-	CREATE TABLE IF NOT EXISTS reviews (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		problem_id INTEGER,
-		quality INTEGER,
-		reviewed_at DATETIME,
-		notes TEXT,
-		interval_snapshot INTEGER,
-		ease_factor_snapshot REAL,
-		FOREIGN KEY (problem_id) REFERENCES problems(id) ON DELETE CASCADE
-	);
-	`
-	if _, err := db.Exec(queryReviews); err != nil {
-		return err
-	}
+// execer is satisfied by both *sql.DB and *sql.Tx, so helpers can run
+// inside or outside a transaction.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
 
-	if err := migrateLegacyReviews(db); err != nil {
-		fmt.Printf("⚠️ Migration warning: %v\n", err)
-	}
+// inTx runs fn in a transaction, committing only if it succeeds. The whole
+// transaction is retried if another process keeps the database busy.
+func (s *Store) inTx(fn func(tx *sql.Tx) error) error {
+	err := retryBusy(func() error {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
 
-	return nil
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+	return missingFTS5(err)
 }
 
-func columnExists(db *sql.DB, tableName, colName string) bool {
-	// Simple check by selecting 1 limit 0
-	_, err := db.Query(fmt.Sprintf("SELECT %s FROM %s LIMIT 0", colName, tableName))
-	return err == nil
+// exec runs a single write statement, retrying while the database is busy.
+func (s *Store) exec(query string, args ...any) (sql.Result, error) {
+	var res sql.Result
+	err := retryBusy(func() (err error) {
+		res, err = s.db.Exec(query, args...)
+		return err
+	})
+	return res, missingFTS5(err)
 }
 
-func (s *Store) AddProblem(p models.Problem) error {
-	res, err := s.db.Exec(`
-		INSERT INTO problems (name, url, notes, difficulty, interval, ease_factor, last_reviewed, next_review)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-		p.Name, p.URL, p.Notes, p.Difficulty, p.Interval, p.EaseFactor, p.LastReviewed, p.NextReview,
+// AddProblem stores a new problem and links its tags in one transaction.
+func (s *Store) AddProblem(p models.Problem) (int, error) {
+	var id int
+	err := s.inTx(func(tx *sql.Tx) (err error) {
+		id, err = addProblem(tx, p)
+		return err
+	})
+	return id, err
+}
+
+func addProblem(tx *sql.Tx, p models.Problem) (int, error) {
+	res, err := tx.Exec(`
+		INSERT INTO problems (name, url, notes, difficulty, interval, ease_factor, last_reviewed, next_review, stability, fsrs_difficulty, state, step, lapses, suspended, box)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		p.Name, p.URL, p.Notes, p.Difficulty, p.Interval, p.EaseFactor, p.LastReviewed, p.NextReview, p.Stability, p.FSRSDifficulty, stateOrDefault(p.State), p.Step, p.Lapses, p.Suspended, p.Box,
 	)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	id, err := res.LastInsertId()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// Add tags
 	for _, tag := range p.Tags {
-		if err := s.linkTag(int(id), tag.Name); err != nil {
-			return err
+		if err := linkTag(tx, int(id), tag.Name); err != nil {
+			return 0, err
 		}
 	}
 
-	return nil
+	return int(id), nil
 }
 
-func (s *Store) linkTag(problemID int, tagName string) error {
+func linkTag(e execer, problemID int, tagName string) error {
 	tagName = strings.TrimSpace(tagName)
 	if tagName == "" {
 		return nil
 	}
 
 	// Ensure tag exists
-	_, err := s.db.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, tagName)
+	_, err := e.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, tagName)
 	if err != nil {
 		return err
 	}
 
 	// Get Tag ID
 	var tagID int
-	err = s.db.QueryRow("SELECT id FROM tags WHERE name = ?", tagName).Scan(&tagID)
+	err = e.QueryRow("SELECT id FROM tags WHERE name = ?", tagName).Scan(&tagID)
 	if err != nil {
 		return err
 	}
 
 	// Link
-	_, err = s.db.Exec(`INSERT OR IGNORE INTO problem_tags (problem_id, tag_id) VALUES (?, ?)`, problemID, tagID)
+	_, err = e.Exec(`INSERT OR IGNORE INTO problem_tags (problem_id, tag_id) VALUES (?, ?)`, problemID, tagID)
 	return err
 }
 
-func (s *Store) GetProblem(name string) (*models.Problem, error) {
-	row := s.db.QueryRow(`
-		SELECT id, name, url, notes, difficulty, interval, ease_factor, last_reviewed, next_review
-		FROM problems WHERE name = ?`, name)
-	
+// problemColumns lists the problems columns in the order scanProblem expects.
+const problemColumns = `id, name, url, notes, difficulty, interval, ease_factor, last_reviewed, next_review, stability, fsrs_difficulty, state, step, lapses, suspended, box`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProblem(row rowScanner) (models.Problem, error) {
 	var p models.Problem
 	var url, notes sql.NullString
-	err := row.Scan(&p.ID, &p.Name, &url, &notes, &p.Difficulty, &p.Interval, &p.EaseFactor, &p.LastReviewed, &p.NextReview)
+	var state string
+	err := row.Scan(&p.ID, &p.Name, &url, &notes, &p.Difficulty, &p.Interval, &p.EaseFactor, &p.LastReviewed, &p.NextReview, &p.Stability, &p.FSRSDifficulty, &state, &p.Step, &p.Lapses, &p.Suspended, &p.Box)
 	if err != nil {
-		return nil, err
+		return p, err
 	}
 	p.URL = url.String
 	p.Notes = notes.String
-	
-	p.Tags, _ = s.getTagsForProblem(p.ID)
-	return &p, nil
+	p.State = models.State(state)
+	return p, nil
+}
+
+// stateOrDefault stores problems created without a learning state as graduated.
+func stateOrDefault(state models.State) models.State {
+	if state == "" {
+		return models.StateReview
+	}
+	return state
+}
+
+// AddTag links an extra tag to a problem, keeping its existing tags.
+func (s *Store) AddTag(problemID int, tagName string) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		return linkTag(tx, problemID, tagName)
+	})
+}
+
+// SetSuspended suspends or unsuspends a problem.
+func (s *Store) SetSuspended(problemID int, suspended bool) error {
+	_, err := s.exec("UPDATE problems SET suspended=? WHERE id=?", suspended, problemID)
+	return err
+}
+
+func (s *Store) GetProblem(name string) (*models.Problem, error) {
+	return s.getProblem(`WHERE name = ?`, name)
+}
+
+// GetProblemByID returns the problem with the given ID.
+func (s *Store) GetProblemByID(id int) (*models.Problem, error) {
+	return s.getProblem(`WHERE id = ?`, id)
+}
+
+func (s *Store) FindProblems(query string) ([]models.Problem, error) {
+	// IDs and exact names are the most precise matches and are indexed, so
+	// look them up before scanning every name.
+	query = strings.TrimSpace(query)
+	if id, err := strconv.Atoi(query); err == nil {
+		if p, err := s.GetProblemByID(id); err == nil {
+			return []models.Problem{*p}, nil
+		} else if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+	if p, err := s.GetProblem(query); err == nil {
+		return []models.Problem{*p}, nil
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`SELECT id, name, url FROM problems`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []models.Problem
+	for rows.Next() {
+		var p models.Problem
+		var url sql.NullString
+		if err := rows.Scan(&p.ID, &p.Name, &url); err != nil {
+			return nil, err
+		}
+		p.URL = url.String
+		candidates = append(candidates, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	matches := matchProblems(query, candidates)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	ids := make([]int, len(matches))
+	for i, p := range matches {
+		ids[i] = p.ID
+	}
+	problems, err := s.problemsByID(ids)
+	if err != nil {
+		return nil, err
+	}
+	sortByName(problems)
+	return problems, nil
+}
+
+// problemsByID loads the given problems in full, ordered by next review,
+// with one query; json_each takes any number of IDs, unlike a list of
+// placeholders.
+func (s *Store) problemsByID(ids []int) ([]models.Problem, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	list := make([]string, len(ids))
+	for i, id := range ids {
+		list[i] = strconv.Itoa(id)
+	}
+	where := `WHERE id IN (SELECT value FROM json_each(?))`
+	order, err := orderClause("due", false, where)
+	if err != nil {
+		return nil, err
+	}
+	return s.listProblems(where, order, 0, "["+strings.Join(list, ",")+"]")
+}
+
+func (s *Store) getProblem(where string, arg any) (*models.Problem, error) {
+	problems, err := s.listProblems(where, "", 0, arg)
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) == 0 {
+		return nil, ErrNotFound
+	}
+	return &problems[0], nil
 }
 
 func (s *Store) UpdateProblem(p models.Problem) error {
-	_, err := s.db.Exec(`
+	return s.inTx(func(tx *sql.Tx) error {
+		return updateSchedule(tx, p)
+	})
+}
+
+// updateSchedule saves the scheduling state of p.
+func updateSchedule(e execer, p models.Problem) error {
+	_, err := e.Exec(`
 		UPDATE problems
-		SET difficulty=?, interval=?, ease_factor=?, last_reviewed=?, next_review=?
+		SET difficulty=?, interval=?, ease_factor=?, last_reviewed=?, next_review=?, stability=?, fsrs_difficulty=?, state=?, step=?, lapses=?, box=?
 		WHERE id=?`,
-		p.Difficulty, p.Interval, p.EaseFactor, p.LastReviewed, p.NextReview, p.ID,
+		p.Difficulty, p.Interval, p.EaseFactor, p.LastReviewed, p.NextReview, p.Stability, p.FSRSDifficulty, stateOrDefault(p.State), p.Step, p.Lapses, p.Box, p.ID,
 	)
 	return err
 }
 
+// UpdateProblemDetails saves the name, URL, notes, difficulty, suspension
+// and tags of p in one transaction, replacing its previous tags.
 func (s *Store) UpdateProblemDetails(p models.Problem) error {
-	// 1. Update core fields
-	query := `
+	return s.inTx(func(tx *sql.Tx) error {
+		return updateDetails(tx, p)
+	})
+}
+
+// SaveProblem saves both the details and the scheduling state of p in one
+// transaction.
+func (s *Store) SaveProblem(p models.Problem) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if err := updateDetails(tx, p); err != nil {
+			return err
+		}
+		return updateSchedule(tx, p)
+	})
+}
+
+func updateDetails(tx *sql.Tx, p models.Problem) error {
+	_, err := tx.Exec(`
 		UPDATE problems
-		SET name=?, url=?, notes=?, difficulty=?
-		WHERE id=?
-	`
-	_, err := s.db.Exec(query, p.Name, p.URL, p.Notes, p.Difficulty, p.ID)
+		SET name=?, url=?, notes=?, difficulty=?, suspended=?
+		WHERE id=?`,
+		p.Name, p.URL, p.Notes, p.Difficulty, p.Suspended, p.ID,
+	)
 	if err != nil {
 		return err
 	}
 
-	// 2. Update tags (Full replace strategy: Delete all then re-add works, or diff. 
-	//    For MVP CLI, clear and re-add is simplest safe approach)
-	
-	// Only update tags if the list is not nil (empty list might mean remove all, nil means don't touch)
-	// But in our struct Tag is a slice, so it's either empty or has items. 
-	// We need a way to know if we should update tags.
-	// For now, let's assume if this is called, we want to set the tags to whatever is in p.Tags
-	// Note: careful if we just want to update name and keep tags. 
-	// The caller should ideally provide the full object or we need a way to partial update.
-	// Given the CLI 'edit' command will probably read existing, apply flags, then save, 
-	// we can assume p.Tags contains the final desired state.
-
-	_, err = s.db.Exec("DELETE FROM problem_tags WHERE problem_id=?", p.ID)
-	if err != nil {
+	// p.Tags is the complete new tag list, so clear and re-link.
+	if _, err := tx.Exec("DELETE FROM problem_tags WHERE problem_id=?", p.ID); err != nil {
 		return err
 	}
-
 	for _, tag := range p.Tags {
-		if err := s.linkTag(p.ID, tag.Name); err != nil {
+		if err := linkTag(tx, p.ID, tag.Name); err != nil {
 			return err
 		}
 	}
-
 	return nil
 }
 
+// RescheduleProblems saves the scheduling state of every problem in a
+// single transaction, so either all of them are rewritten or none are.
+func (s *Store) RescheduleProblems(problems []models.Problem) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(`
+			UPDATE problems
+			SET interval=?, ease_factor=?, last_reviewed=?, next_review=?, stability=?, fsrs_difficulty=?, state=?, step=?, lapses=?, box=?
+			WHERE id=?`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, p := range problems {
+			if _, err := stmt.Exec(p.Interval, p.EaseFactor, p.LastReviewed, p.NextReview, p.Stability, p.FSRSDifficulty, stateOrDefault(p.State), p.Step, p.Lapses, p.Box, p.ID); err != nil {
+				return fmt.Errorf("problem %d: %w", p.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteProblem removes a problem; ON DELETE CASCADE removes its tags and
+// reviews in the same statement.
 func (s *Store) DeleteProblem(id int) error {
-	// Cascading delete should handle problem_tags if defined in schema, 
-	// but let's be explicit if schema didn't have cascade (which we did put in initSchema).
-	_, err := s.db.Exec("DELETE FROM problems WHERE id=?", id)
+	_, err := s.exec("DELETE FROM problems WHERE id=?", id)
 	return err
 }
 
 func (s *Store) ListProblems(dueOnly bool) ([]models.Problem, error) {
-	var query string
+	var filter query.Expr
 	if dueOnly {
-		query = `SELECT id, name, url, notes, difficulty, interval, ease_factor, last_reviewed, next_review FROM problems WHERE date(next_review) <= date('now') ORDER BY next_review ASC`
-	} else {
-		query = `SELECT id, name, url, notes, difficulty, interval, ease_factor, last_reviewed, next_review FROM problems ORDER BY next_review ASC`
+		filter = query.Is("due")
 	}
+	return s.QueryProblems(query.Query{Filter: filter})
+}
 
-	rows, err := s.db.Query(query)
+// listProblems returns the problems selected by the where clause, in the
+// order given by an ORDER BY clause and at most limit of them unless limit
+// is 0, with their tags. Either clause may be empty.
+func (s *Store) listProblems(where, order string, limit int, args ...any) ([]models.Problem, error) {
+	clause := where + order
+	if limit > 0 {
+		clause += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := s.db.Query(`SELECT `+problemColumns+` FROM problems `+clause, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -272,113 +403,265 @@ func (s *Store) ListProblems(dueOnly bool) ([]models.Problem, error) {
 
 	var problems []models.Problem
 	for rows.Next() {
-		var p models.Problem
-		var url, notes sql.NullString
-		if err := rows.Scan(&p.ID, &p.Name, &url, &notes, &p.Difficulty, &p.Interval, &p.EaseFactor, &p.LastReviewed, &p.NextReview); err != nil {
+		p, err := scanProblem(rows)
+		if err != nil {
 			return nil, err
 		}
-		p.URL = url.String
-		p.Notes = notes.String
-		// Optimization: Could fetch tags in batch, but for CLI loop is okay
-		p.Tags, _ = s.getTagsForProblem(p.ID)
 		problems = append(problems, p)
 	}
-	return problems, nil
-}
-
-func (s *Store) getTagsForProblem(problemID int) ([]models.Tag, error) {
-	rows, err := s.db.Query(`
-		SELECT t.id, t.name 
-		FROM tags t
-		JOIN problem_tags pt ON t.id = pt.tag_id
-		WHERE pt.problem_id = ?`, problemID)
-	if err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	rows.Close()
 
-	var tags []models.Tag
-	for rows.Next() {
-		var t models.Tag
-		rows.Scan(&t.ID, &t.Name)
-		tags = append(tags, t)
+	if where == "" && limit == 0 {
+		clause, args = "", nil // Every problem, so load every tag
+	}
+	if err := s.attachTags(problems, clause, args...); err != nil {
+		return nil, err
 	}
-	return tags, nil
+	return problems, nil
 }
 
-// migrateLegacyReviews backfills the reviews table from problems that have been reviewed but have no history.
-func migrateLegacyReviews(db *sql.DB) error {
-	// Find problems that have been reviewed (last_reviewed > epoch)
-	rows, err := db.Query(`SELECT id, last_reviewed, interval, ease_factor FROM problems WHERE last_reviewed IS NOT NULL And last_reviewed > '1970-01-01'`)
+// CountDueOn returns how many active problems are scheduled on the study
+// day containing t.
+func (s *Store) CountDueOn(t time.Time) (int, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM problems
+		WHERE suspended = 0 AND datetime(next_review) >= datetime(?) AND datetime(next_review) < datetime(?)`,
+		s.calendar.DayStart(t), s.calendar.DayEnd(t),
+	).Scan(&count)
+	return count, err
+}
+
+// attachTags loads the tags of problems, which were selected from the
+// problems table by clause and args, in a single query rather than one
+// query per problem.
+func (s *Store) attachTags(problems []models.Problem, clause string, args ...any) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	index := make(map[int]int, len(problems))
+	for i := range problems {
+		index[problems[i].ID] = i
+	}
+
+	tagQuery := `
+		SELECT pt.problem_id, t.id, t.name, t.preset
+		FROM problem_tags pt
+		JOIN tags t ON t.id = pt.tag_id`
+	if clause != "" {
+		tagQuery += ` WHERE pt.problem_id IN (SELECT id FROM problems ` + clause + `)`
+	}
+	rows, err := s.db.Query(tagQuery+` ORDER BY pt.problem_id, t.id`, args...)
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
 
-	type legacyProb struct {
-		ID           int
-		LastReviewed time.Time
-		Interval     int
-		EaseFactor   float64
-	}
-
-	var toMigrate []legacyProb
-
 	for rows.Next() {
-		var p legacyProb
-		if err := rows.Scan(&p.ID, &p.LastReviewed, &p.Interval, &p.EaseFactor); err != nil {
+		var problemID int
+		var t models.Tag
+		var preset sql.NullString
+		if err := rows.Scan(&problemID, &t.ID, &t.Name, &preset); err != nil {
+			return err
+		}
+		// A problem may have changed between the two queries.
+		i, ok := index[problemID]
+		if !ok {
 			continue
 		}
-		toMigrate = append(toMigrate, p)
+		t.Preset = preset.String
+		problems[i].Tags = append(problems[i].Tags, t)
 	}
+	return rows.Err()
+}
 
-	for _, p := range toMigrate {
-		// Check if any review exists
-		var count int
-		err := db.QueryRow("SELECT COUNT(*) FROM reviews WHERE problem_id = ?", p.ID).Scan(&count)
-		if err != nil {
-			continue
-		}
+func (s *Store) AddReview(r models.Review) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		return addReview(tx, r)
+	})
+}
 
-		if count == 0 {
-			// Insert synthetic review
-			_, err := db.Exec(`
-				INSERT INTO reviews (problem_id, quality, reviewed_at, notes, interval_snapshot, ease_factor_snapshot)
-				VALUES (?, ?, ?, ?, ?, ?)`,
-				p.ID, 3, p.LastReviewed, "Imported from legacy data", p.Interval, p.EaseFactor,
-			)
-			if err != nil {
-				fmt.Printf("Failed to migrate problem %d: %v\n", p.ID, err)
+// RecordReview saves the new scheduling state of a reviewed problem and
+// adds the review to its history in one transaction, so a problem is never
+// rescheduled without a matching history row.
+func (s *Store) RecordReview(p models.Problem, r models.Review) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if err := updateSchedule(tx, p); err != nil {
+			return err
+		}
+		// A review can make the problem a leech, which tags and may
+		// suspend it.
+		if _, err := tx.Exec("UPDATE problems SET suspended=? WHERE id=?", p.Suspended, p.ID); err != nil {
+			return err
+		}
+		for _, t := range p.Tags {
+			if err := linkTag(tx, p.ID, t.Name); err != nil {
+				return err
 			}
 		}
-	}
-	return nil
+		return addReview(tx, r)
+	})
 }
 
-func (s *Store) AddReview(r models.Review) error {
-	_, err := s.db.Exec(`
-		INSERT INTO reviews (problem_id, quality, reviewed_at, notes, interval_snapshot, ease_factor_snapshot)
-		VALUES (?, ?, ?, ?, ?, ?)`,
-		r.ProblemID, r.Quality, r.ReviewedAt, r.Notes, r.Interval, r.EaseFactor,
+func addReview(e execer, r models.Review) error {
+	_, err := e.Exec(`
+		INSERT INTO reviews (problem_id, quality, reviewed_at, notes, interval_snapshot, ease_factor_snapshot, stability_snapshot, fsrs_difficulty_snapshot, scheduled_interval, actual_interval)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ProblemID, r.Quality, r.ReviewedAt, r.Notes, r.Interval, r.EaseFactor, r.Stability, r.FSRSDifficulty, r.ScheduledInterval, r.ActualInterval,
 	)
 	return err
 }
 
 func (s *Store) GetLastReview(problemID int) (*models.Review, error) {
 	row := s.db.QueryRow(`
-		SELECT id, problem_id, quality, reviewed_at, notes, interval_snapshot, ease_factor_snapshot
-		FROM reviews 
-		WHERE problem_id = ? 
-		ORDER BY reviewed_at DESC 
+		SELECT `+reviewColumns+`
+		FROM reviews
+		WHERE problem_id = ?
+		ORDER BY reviewed_at DESC
 		LIMIT 1`, problemID)
 
+	r, err := scanReview(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ListReviews returns the full review log ordered by problem and review time.
+func (s *Store) ListReviews() ([]models.Review, error) {
+	rows, err := s.db.Query(`SELECT ` + reviewColumns + ` FROM reviews ORDER BY problem_id, reviewed_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []models.Review
+	for rows.Next() {
+		r, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, rows.Err()
+}
+
+// reviewColumns lists the reviews columns in the order scanReview expects.
+const reviewColumns = `id, problem_id, quality, reviewed_at, notes, interval_snapshot, ease_factor_snapshot, stability_snapshot, fsrs_difficulty_snapshot, scheduled_interval, actual_interval`
+
+func scanReview(row rowScanner) (models.Review, error) {
 	var r models.Review
 	var notes sql.NullString
-	if err := row.Scan(&r.ID, &r.ProblemID, &r.Quality, &r.ReviewedAt, &notes, &r.Interval, &r.EaseFactor); err != nil {
-		return nil, err
+	err := row.Scan(&r.ID, &r.ProblemID, &r.Quality, &r.ReviewedAt, &notes, &r.Interval, &r.EaseFactor, &r.Stability, &r.FSRSDifficulty, &r.ScheduledInterval, &r.ActualInterval)
+	if err != nil {
+		return r, err
 	}
 	r.Notes = notes.String
-	return &r, nil
+	return r, nil
+}
+
+// ListPresets returns every scheduler preset ordered by name.
+func (s *Store) ListPresets() ([]models.Preset, error) {
+	rows, err := s.db.Query(`
+		SELECT name, max_interval, ease_floor, target_retention, learning_steps, relearning_steps
+		FROM presets ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var presets []models.Preset
+	for rows.Next() {
+		var p models.Preset
+		if err := rows.Scan(&p.Name, &p.MaxInterval, &p.EaseFloor, &p.TargetRetention, &p.LearningSteps, &p.RelearningSteps); err != nil {
+			return nil, err
+		}
+		presets = append(presets, p)
+	}
+	return presets, rows.Err()
+}
+
+// SavePreset creates a preset or replaces the one with the same name.
+func (s *Store) SavePreset(p models.Preset) error {
+	_, err := s.exec(`
+		INSERT INTO presets (name, max_interval, ease_floor, target_retention, learning_steps, relearning_steps)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			max_interval = excluded.max_interval,
+			ease_floor = excluded.ease_floor,
+			target_retention = excluded.target_retention,
+			learning_steps = excluded.learning_steps,
+			relearning_steps = excluded.relearning_steps`,
+		p.Name, p.MaxInterval, p.EaseFloor, p.TargetRetention, p.LearningSteps, p.RelearningSteps,
+	)
+	return err
+}
+
+// DeletePreset removes a preset and unassigns it from its tags.
+func (s *Store) DeletePreset(name string) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("UPDATE tags SET preset = '' WHERE preset = ?", name); err != nil {
+			return err
+		}
+		_, err := tx.Exec("DELETE FROM presets WHERE name = ?", name)
+		return err
+	})
+}
+
+// AssignPreset makes problems tagged tagName use the named preset, creating
+// the tag if needed. An empty preset unassigns it.
+func (s *Store) AssignPreset(tagName, preset string) error {
+	tagName = strings.TrimSpace(tagName)
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, tagName); err != nil {
+			return err
+		}
+		_, err := tx.Exec("UPDATE tags SET preset = ? WHERE name = ?", preset, tagName)
+		return err
+	})
+}
+
+// PresetAssignments returns the preset assigned to each tag that has one.
+func (s *Store) PresetAssignments() (map[string]string, error) {
+	rows, err := s.db.Query("SELECT name, preset FROM tags WHERE preset != '' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := make(map[string]string)
+	for rows.Next() {
+		var tag, preset string
+		if err := rows.Scan(&tag, &preset); err != nil {
+			return nil, err
+		}
+		assignments[tag] = preset
+	}
+	return assignments, rows.Err()
+}
+
+// GetSetting returns the value stored under key, or "" if it is unset.
+func (s *Store) GetSetting(key string) (string, error) {
+	var value string
+	err := s.db.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+// SetSetting stores value under key, replacing any previous value.
+func (s *Store) SetSetting(key, value string) error {
+	_, err := s.exec(`
+		INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value`, key, value)
+	return err
 }
 
 func (s *Store) GetReviewStats() (*models.ReviewStats, error) {
@@ -391,8 +674,9 @@ func (s *Store) GetReviewStats() (*models.ReviewStats, error) {
 		return nil, err
 	}
 
-	// Reviews Last 7 Days
-	if err := s.db.QueryRow("SELECT COUNT(*) FROM reviews WHERE reviewed_at > date('now', '-7 days')").Scan(&stats.ReviewsLast7Days); err != nil {
+	// Reviews Last 7 Days (today and the six study days before it)
+	weekStart := s.calendar.DayStart(s.clock.Now()).AddDate(0, 0, -6)
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM reviews WHERE datetime(reviewed_at) >= datetime(?)", weekStart).Scan(&stats.ReviewsLast7Days); err != nil {
 		return nil, err
 	}
 
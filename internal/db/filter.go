@@ -0,0 +1,179 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/LavenderBridge/spaced-repetition/internal/query"
+)
+
+// dueCondition selects the problems due at the second argument, whose
+// study day ends at the first. Reviews are due once their day has come;
+// learning steps are scheduled in minutes and only due once that time has
+// passed. datetime() normalizes stored timezone offsets so instants compare
+// correctly, and leading with the day's end lets SQLite narrow the rows
+// down with idx_problems_next_review_at.
+const dueCondition = `datetime(next_review) < datetime(?) AND suspended = 0
+	AND (state NOT IN ('new', 'learning', 'relearning') OR datetime(next_review) <= datetime(?))`
+
+// taggedWith selects the IDs of problems with the tag given as argument.
+const taggedWith = `id IN (SELECT pt.problem_id FROM problem_tags pt JOIN tags t ON t.id = pt.tag_id WHERE t.name = ? COLLATE NOCASE)`
+
+var fieldColumns = map[query.Field]string{
+	query.FieldDifficulty: "difficulty",
+	query.FieldInterval:   "interval",
+	query.FieldEase:       "ease_factor",
+	query.FieldLapses:     "lapses",
+	query.FieldBox:        "box",
+}
+
+// compileFilter translates e into a condition on the problems table with
+// the same meaning as query.Match, or "" if e is nil.
+func compileFilter(e query.Expr, env query.Env) (string, []any) {
+	if e == nil {
+		return "", nil
+	}
+	var args []any
+	arg := func(values ...any) { args = append(args, values...) }
+
+	var compile func(e query.Expr) string
+	join := func(terms []query.Expr, op, empty string) string {
+		if len(terms) == 0 {
+			return empty
+		}
+		parts := make([]string, len(terms))
+		for i, t := range terms {
+			parts[i] = "(" + compile(t) + ")"
+		}
+		return strings.Join(parts, " "+op+" ")
+	}
+	compile = func(e query.Expr) string {
+		switch e := e.(type) {
+		case query.And:
+			return join(e, "AND", "1")
+		case query.Or:
+			return join(e, "OR", "0")
+		case query.Not:
+			return "NOT (" + compile(e.X) + ")"
+		case query.Tag:
+			arg(string(e))
+			return taggedWith
+		case query.Name:
+			arg(strings.ToLower(string(e)))
+			return `instr(lower(name), ?) > 0`
+		case query.Is:
+			return isCondition(e, env, arg)
+		case query.Compare:
+			column := fieldColumns[e.Field]
+			if e.Op == query.Between {
+				arg(e.Value, e.Max)
+				return column + ` BETWEEN ? AND ?`
+			}
+			arg(e.Value)
+			return column + ` ` + string(e.Op) + ` ?`
+		case query.Days:
+			column, cond := "next_review", ""
+			if e.Field == query.DateReviewed {
+				// Never-reviewed problems have no review to date.
+				column, cond = "last_reviewed", `state <> 'new'`
+			}
+			from, to := e.Bounds(env.Now, env.Calendar)
+			conds := []string{}
+			if cond != "" {
+				conds = append(conds, cond)
+			}
+			if !from.IsZero() {
+				arg(from)
+				conds = append(conds, `datetime(`+column+`) >= datetime(?)`)
+			}
+			if !to.IsZero() {
+				arg(to)
+				conds = append(conds, `datetime(`+column+`) < datetime(?)`)
+			}
+			return strings.Join(conds, " AND ")
+		}
+		panic(fmt.Sprintf("db: unknown filter expression %T", e))
+	}
+	return compile(e), args
+}
+
+func isCondition(e query.Is, env query.Env, arg func(...any)) string {
+	switch e {
+	case "due":
+		arg(env.Calendar.DayEnd(env.Now), env.Now)
+		return dueCondition
+	case "new":
+		return `state = 'new'`
+	case "learning":
+		return `state IN ('learning', 'relearning')`
+	case "review":
+		return `state = 'review'`
+	case "suspended":
+		return `suspended = 1`
+	case "leech":
+		arg(models.LeechTag)
+		if env.LeechThreshold <= 0 {
+			return taggedWith
+		}
+		arg(env.LeechThreshold)
+		return taggedWith + ` OR lapses >= ?`
+	}
+	return "0"
+}
+
+// sortColumns gives the ORDER BY of each sort key, matching the natural
+// orders of query.Sort. Times are sorted as datetime() instants, since
+// stored timestamps may carry different timezone offsets.
+var sortColumns = map[string]string{
+	"due":        "datetime(next_review) ASC",
+	"name":       "name COLLATE NOCASE ASC",
+	"difficulty": "difficulty DESC",
+	"id":         "id ASC",
+	"interval":   "interval ASC",
+	"ease":       "ease_factor ASC",
+	"lapses":     "lapses DESC",
+	"reviewed":   "datetime(last_reviewed) ASC",
+}
+
+// orderClause returns the ORDER BY for a sort key, breaking ties by ID as
+// query.Sort does, for rows selected by where.
+func orderClause(key string, reverse bool, where string) (string, error) {
+	if key == "" {
+		key = "due"
+	}
+	if key == query.SortRecall {
+		return "", query.ErrRecallSort
+	}
+	order, ok := sortColumns[key]
+	if !ok {
+		return "", fmt.Errorf("unknown sort key %q (use %s)", key, strings.Join(query.SortKeys, ", "))
+	}
+	if key == "due" && where != "" {
+		// A filter is evaluated on every row anyway, so sorting its
+		// matches is faster than walking idx_problems_next_review_at; the
+		// unary + stops SQLite from using the index for the ORDER BY.
+		order = "+" + order
+	}
+	if key != "id" {
+		order += ", id ASC"
+	}
+	if reverse {
+		order = strings.NewReplacer(" ASC", " DESC", " DESC", " ASC").Replace(order)
+	}
+	return ` ORDER BY ` + order, nil
+}
+
+// QueryProblems returns the problems matching q.Filter, sorted and limited
+// as q asks, with their tags.
+func (s *Store) QueryProblems(q query.Query) ([]models.Problem, error) {
+	where, args := compileFilter(q.Filter, query.Env{Now: s.clock.Now(), Calendar: s.calendar, LeechThreshold: q.LeechThreshold})
+	if where != "" {
+		where = "WHERE " + where
+	}
+	order, err := orderClause(q.Sort, q.Reverse, where)
+	if err != nil {
+		return nil, err
+	}
+	return s.listProblems(where, order, q.Limit, args...)
+}
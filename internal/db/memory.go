@@ -0,0 +1,483 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/LavenderBridge/spaced-repetition/internal/query"
+)
+
+// MemoryStore is a Repository that keeps everything in memory. Closing it
+// keeps its contents, so one MemoryStore can serve several commands.
+type MemoryStore struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	calendar clock.Calendar
+
+	problems    []models.Problem // In insertion order, without tags
+	problemTags map[int][]int    // Problem ID to tag IDs
+	tags        []models.Tag
+	reviews     []models.Review
+	settings    map[string]string
+	presets     map[string]models.Preset
+
+	lastProblemID, lastTagID, lastReviewID int
+}
+
+// NewMemoryStore returns an empty in-memory repository.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		clock:       clock.System(),
+		problemTags: make(map[int][]int),
+		settings:    make(map[string]string),
+		presets:     make(map[string]models.Preset),
+	}
+}
+
+func (m *MemoryStore) Close() error { return nil }
+
+func (m *MemoryStore) SetClock(c clock.Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = c
+}
+
+func (m *MemoryStore) SetCalendar(c clock.Calendar) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calendar = c
+}
+
+func (m *MemoryStore) Calendar() clock.Calendar {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calendar
+}
+
+func (m *MemoryStore) AddProblem(p models.Problem) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.indexByName(p.Name) >= 0 {
+		return 0, fmt.Errorf("problem %q already exists", p.Name)
+	}
+	m.lastProblemID++
+	p.ID = m.lastProblemID
+	p.State = stateOrDefault(p.State)
+	tags := p.Tags
+	p.Tags = nil
+	m.problems = append(m.problems, p)
+	for _, t := range tags {
+		m.linkTag(p.ID, t.Name)
+	}
+	return p.ID, nil
+}
+
+func (m *MemoryStore) indexByID(id int) int {
+	return slices.IndexFunc(m.problems, func(p models.Problem) bool { return p.ID == id })
+}
+
+func (m *MemoryStore) indexByName(name string) int {
+	return slices.IndexFunc(m.problems, func(p models.Problem) bool { return p.Name == name })
+}
+
+// tagID returns the ID of the named tag, creating it if needed.
+func (m *MemoryStore) tagID(name string) int {
+	for _, t := range m.tags {
+		if t.Name == name {
+			return t.ID
+		}
+	}
+	m.lastTagID++
+	m.tags = append(m.tags, models.Tag{ID: m.lastTagID, Name: name})
+	return m.lastTagID
+}
+
+func (m *MemoryStore) linkTag(problemID int, tagName string) {
+	tagName = strings.TrimSpace(tagName)
+	if tagName == "" {
+		return
+	}
+	id := m.tagID(tagName)
+	ids := m.problemTags[problemID]
+	if !slices.Contains(ids, id) {
+		ids = append(ids, id)
+		slices.Sort(ids)
+		m.problemTags[problemID] = ids
+	}
+}
+
+// withTags returns a copy of p carrying its tags.
+func (m *MemoryStore) withTags(p models.Problem) models.Problem {
+	p.Tags = nil
+	for _, id := range m.problemTags[p.ID] {
+		p.Tags = append(p.Tags, m.tags[id-1])
+	}
+	return p
+}
+
+func (m *MemoryStore) AddTag(problemID int, tagName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.indexByID(problemID) < 0 {
+		return nil
+	}
+	m.linkTag(problemID, tagName)
+	return nil
+}
+
+func (m *MemoryStore) SetSuspended(problemID int, suspended bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i := m.indexByID(problemID); i >= 0 {
+		m.problems[i].Suspended = suspended
+	}
+	return nil
+}
+
+func (m *MemoryStore) GetProblem(name string) (*models.Problem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.indexByName(name)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	p := m.withTags(m.problems[i])
+	return &p, nil
+}
+
+func (m *MemoryStore) GetProblemByID(id int) (*models.Problem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.indexByID(id)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	p := m.withTags(m.problems[i])
+	return &p, nil
+}
+
+func (m *MemoryStore) FindProblems(query string) ([]models.Problem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var problems []models.Problem
+	for _, p := range matchProblems(query, m.problems) {
+		problems = append(problems, m.withTags(p))
+	}
+	return problems, nil
+}
+
+// Search matches words by prefix, ignoring case, like Store.Search but
+// without stemming, and ranks problems by their weighted number of hits.
+func (m *MemoryStore) Search(query string, limit int) ([]models.SearchResult, error) {
+	terms := searchWords(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var results []models.SearchResult
+	for _, p := range m.problems {
+		p = m.withTags(p)
+		var tags, notes []string
+		for _, t := range p.Tags {
+			tags = append(tags, t.Name)
+		}
+		for _, r := range m.reviews {
+			if r.ProblemID == p.ID && r.Notes != "" && r.Notes != LegacyReviewNote {
+				notes = append(notes, r.Notes)
+			}
+		}
+		fields := [4]string{p.Name, p.Notes, strings.Join(tags, " "), strings.Join(notes, " ")}
+		if score, snippet := matchFields(fields, terms); score > 0 {
+			results = append(results, models.SearchResult{Problem: p, Snippet: snippet, Rank: -score})
+		}
+	}
+	return rankResults(results, limit), nil
+}
+
+func (m *MemoryStore) UpdateProblem(p models.Problem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reschedule(p)
+	return nil
+}
+
+// reschedule copies the scheduling state of p onto the stored problem.
+func (m *MemoryStore) reschedule(p models.Problem) {
+	i := m.indexByID(p.ID)
+	if i < 0 {
+		return
+	}
+	stored := &m.problems[i]
+	stored.Difficulty = p.Difficulty
+	stored.Interval = p.Interval
+	stored.EaseFactor = p.EaseFactor
+	stored.LastReviewed = p.LastReviewed
+	stored.NextReview = p.NextReview
+	stored.Stability = p.Stability
+	stored.FSRSDifficulty = p.FSRSDifficulty
+	stored.State = stateOrDefault(p.State)
+	stored.Step = p.Step
+	stored.Lapses = p.Lapses
+	stored.Box = p.Box
+}
+
+func (m *MemoryStore) UpdateProblemDetails(p models.Problem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.updateDetails(p)
+}
+
+func (m *MemoryStore) SaveProblem(p models.Problem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.updateDetails(p); err != nil {
+		return err
+	}
+	m.reschedule(p)
+	return nil
+}
+
+// updateDetails copies the details and tags of p onto the stored problem.
+func (m *MemoryStore) updateDetails(p models.Problem) error {
+	i := m.indexByID(p.ID)
+	if i < 0 {
+		return nil
+	}
+	if j := m.indexByName(p.Name); j >= 0 && j != i {
+		return fmt.Errorf("problem %q already exists", p.Name)
+	}
+	stored := &m.problems[i]
+	stored.Name = p.Name
+	stored.URL = p.URL
+	stored.Notes = p.Notes
+	stored.Difficulty = p.Difficulty
+	stored.Suspended = p.Suspended
+
+	delete(m.problemTags, p.ID)
+	for _, t := range p.Tags {
+		m.linkTag(p.ID, t.Name)
+	}
+	return nil
+}
+
+func (m *MemoryStore) RescheduleProblems(problems []models.Problem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Nothing here can fail halfway, so applying them in turn is atomic.
+	for _, p := range problems {
+		m.reschedule(p)
+	}
+	return nil
+}
+
+func (m *MemoryStore) DeleteProblem(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.indexByID(id)
+	if i < 0 {
+		return nil
+	}
+	m.problems = slices.Delete(m.problems, i, i+1)
+	delete(m.problemTags, id)
+	m.reviews = slices.DeleteFunc(m.reviews, func(r models.Review) bool { return r.ProblemID == id })
+	return nil
+}
+
+func (m *MemoryStore) ListProblems(dueOnly bool) ([]models.Problem, error) {
+	var filter query.Expr
+	if dueOnly {
+		filter = query.Is("due")
+	}
+	return m.QueryProblems(query.Query{Filter: filter})
+}
+
+// QueryProblems returns the problems matching q.Filter, sorted and limited
+// as q asks.
+func (m *MemoryStore) QueryProblems(q query.Query) ([]models.Problem, error) {
+	if q.Sort == query.SortRecall {
+		return nil, query.ErrRecallSort
+	}
+	m.mu.Lock()
+	env := query.Env{Now: m.clock.Now(), Calendar: m.calendar, LeechThreshold: q.LeechThreshold}
+	var problems []models.Problem
+	for _, p := range m.problems {
+		if p = m.withTags(p); query.Match(q.Filter, p, env) {
+			problems = append(problems, p)
+		}
+	}
+	m.mu.Unlock()
+
+	if err := query.Sort(problems, q.Sort, q.Reverse); err != nil {
+		return nil, err
+	}
+	if q.Limit > 0 && len(problems) > q.Limit {
+		problems = problems[:q.Limit]
+	}
+	return problems, nil
+}
+
+func (m *MemoryStore) CountDueOn(t time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	start, end := m.calendar.DayStart(t), m.calendar.DayEnd(t)
+	count := 0
+	for _, p := range m.problems {
+		if !p.Suspended && !p.NextReview.Before(start) && p.NextReview.Before(end) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MemoryStore) AddReview(r models.Review) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.addReview(r)
+	return nil
+}
+
+func (m *MemoryStore) RecordReview(p models.Problem, r models.Review) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reschedule(p)
+	if i := m.indexByID(p.ID); i >= 0 {
+		m.problems[i].Suspended = p.Suspended
+		for _, t := range p.Tags {
+			m.linkTag(p.ID, t.Name)
+		}
+	}
+	m.addReview(r)
+	return nil
+}
+
+func (m *MemoryStore) addReview(r models.Review) {
+	m.lastReviewID++
+	r.ID = m.lastReviewID
+	m.reviews = append(m.reviews, r)
+}
+
+func (m *MemoryStore) GetLastReview(problemID int) (*models.Review, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var last *models.Review
+	for i, r := range m.reviews {
+		if r.ProblemID == problemID && (last == nil || !r.ReviewedAt.Before(last.ReviewedAt)) {
+			last = &m.reviews[i]
+		}
+	}
+	if last == nil {
+		return nil, ErrNotFound
+	}
+	r := *last
+	return &r, nil
+}
+
+func (m *MemoryStore) ListReviews() ([]models.Review, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	reviews := slices.Clone(m.reviews)
+	sort.SliceStable(reviews, func(i, j int) bool {
+		if reviews[i].ProblemID != reviews[j].ProblemID {
+			return reviews[i].ProblemID < reviews[j].ProblemID
+		}
+		return reviews[i].ReviewedAt.Before(reviews[j].ReviewedAt)
+	})
+	return reviews, nil
+}
+
+func (m *MemoryStore) GetReviewStats() (*models.ReviewStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := &models.ReviewStats{
+		TotalReviews:      len(m.reviews),
+		CountByDifficulty: make(map[int]int),
+	}
+	weekStart := m.calendar.DayStart(m.clock.Now()).AddDate(0, 0, -6)
+	total := 0
+	for _, r := range m.reviews {
+		if !r.ReviewedAt.Before(weekStart) {
+			stats.ReviewsLast7Days++
+		}
+		total += r.Quality
+	}
+	if len(m.reviews) > 0 {
+		stats.AverageQuality = float64(total) / float64(len(m.reviews))
+	}
+	for _, p := range m.problems {
+		stats.CountByDifficulty[p.Difficulty]++
+	}
+	return stats, nil
+}
+
+func (m *MemoryStore) GetSetting(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.settings[key], nil
+}
+
+func (m *MemoryStore) SetSetting(key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settings[key] = value
+	return nil
+}
+
+func (m *MemoryStore) ListPresets() ([]models.Preset, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var presets []models.Preset
+	for _, p := range m.presets {
+		presets = append(presets, p)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets, nil
+}
+
+func (m *MemoryStore) SavePreset(p models.Preset) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.presets[p.Name] = p
+	return nil
+}
+
+func (m *MemoryStore) DeletePreset(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.tags {
+		if m.tags[i].Preset == name {
+			m.tags[i].Preset = ""
+		}
+	}
+	delete(m.presets, name)
+	return nil
+}
+
+func (m *MemoryStore) AssignPreset(tagName, preset string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := m.tagID(strings.TrimSpace(tagName))
+	m.tags[id-1].Preset = preset
+	return nil
+}
+
+func (m *MemoryStore) PresetAssignments() (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	assignments := make(map[string]string)
+	for _, t := range m.tags {
+		if t.Preset != "" {
+			assignments[t.Name] = t.Preset
+		}
+	}
+	return assignments, nil
+}
@@ -0,0 +1,307 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// migration is one numbered step of the schema. Steps are applied in
+// order, each in its own transaction, and recorded in schema_migrations so
+// they run exactly once per database.
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+}
+
+// Migration reports the state of one schema migration.
+type Migration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time // Zero if the migration is pending
+}
+
+// LegacyReviewNote marks synthetic reviews backfilled by the
+// backfill_legacy_reviews migration.
+const LegacyReviewNote = "Imported from legacy data"
+
+// migrations lists every schema change in the order it was introduced.
+// Databases created before migrations were tracked may already have some
+// of these columns, so column additions go through addColumn. Never edit
+// or reorder an existing entry; append a new one instead.
+var migrations = []migration{
+	{1, "create_problems_and_tags", execAll(`
+		CREATE TABLE IF NOT EXISTS problems (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT UNIQUE NOT NULL,
+			url TEXT,
+			notes TEXT,
+			difficulty INTEGER NOT NULL,
+			interval INTEGER DEFAULT 1,
+			ease_factor REAL DEFAULT 2.5,
+			last_reviewed DATE NOT NULL,
+			next_review DATE NOT NULL
+		)`, `
+		CREATE TABLE IF NOT EXISTS tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT UNIQUE NOT NULL
+		)`, `
+		CREATE TABLE IF NOT EXISTS problem_tags (
+			problem_id INTEGER,
+			tag_id INTEGER,
+			PRIMARY KEY (problem_id, tag_id),
+			FOREIGN KEY (problem_id) REFERENCES problems(id) ON DELETE CASCADE,
+			FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+		)`)},
+	{2, "add_problem_details", addColumns("problems",
+		"url TEXT",
+		"notes TEXT",
+		"interval INTEGER DEFAULT 1",
+		"ease_factor REAL DEFAULT 2.5")},
+	{3, "create_reviews", execAll(`
+		CREATE TABLE IF NOT EXISTS reviews (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			problem_id INTEGER,
+			quality INTEGER,
+			reviewed_at DATETIME,
+			notes TEXT,
+			interval_snapshot INTEGER,
+			ease_factor_snapshot REAL,
+			FOREIGN KEY (problem_id) REFERENCES problems(id) ON DELETE CASCADE
+		)`)},
+	{4, "backfill_legacy_reviews", backfillLegacyReviews},
+	{5, "add_fsrs_state", func(tx *sql.Tx) error {
+		if err := addColumns("problems", "stability REAL DEFAULT 0", "fsrs_difficulty REAL DEFAULT 0")(tx); err != nil {
+			return err
+		}
+		return addColumns("reviews", "stability_snapshot REAL DEFAULT 0", "fsrs_difficulty_snapshot REAL DEFAULT 0")(tx)
+	}},
+	{6, "create_settings", execAll(`
+		CREATE TABLE IF NOT EXISTS settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		)`)},
+	{7, "add_learning_state", addColumns("problems", "state TEXT DEFAULT 'review'", "step INTEGER DEFAULT 0")},
+	{8, "add_lapses_and_suspension", addColumns("problems", "lapses INTEGER DEFAULT 0", "suspended INTEGER DEFAULT 0")},
+	{9, "add_review_intervals", addColumns("reviews", "scheduled_interval INTEGER DEFAULT 0", "actual_interval INTEGER DEFAULT 0")},
+	{10, "add_leitner_box", addColumns("problems", "box INTEGER DEFAULT 0")},
+	{11, "create_presets", func(tx *sql.Tx) error {
+		err := execAll(`
+		CREATE TABLE IF NOT EXISTS presets (
+			name TEXT PRIMARY KEY,
+			max_interval INTEGER DEFAULT 0,
+			ease_floor REAL DEFAULT 0,
+			target_retention REAL DEFAULT 0,
+			learning_steps TEXT DEFAULT '',
+			relearning_steps TEXT DEFAULT ''
+		)`)(tx)
+		if err != nil {
+			return err
+		}
+		return addColumns("tags", "preset TEXT DEFAULT ''")(tx)
+	}},
+	// Foreign keys were not enforced before, so deleting a problem left
+	// its tag links and reviews behind.
+	{12, "remove_orphaned_rows", execAll(
+		`DELETE FROM problem_tags WHERE problem_id NOT IN (SELECT id FROM problems) OR tag_id NOT IN (SELECT id FROM tags)`,
+		`DELETE FROM reviews WHERE problem_id NOT IN (SELECT id FROM problems)`,
+	)},
+	{13, "add_indexes", execAll(
+		`CREATE INDEX IF NOT EXISTS idx_problems_next_review ON problems(next_review)`,
+		`CREATE INDEX IF NOT EXISTS idx_problem_tags_tag_id ON problem_tags(tag_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_reviews_problem_reviewed_at ON reviews(problem_id, reviewed_at)`,
+	)},
+	{14, "create_search_index", createSearchIndex},
+	// Due dates are compared as datetime(next_review), which
+	// idx_problems_next_review cannot serve.
+	{15, "index_next_review_at", execAll(
+		`CREATE INDEX IF NOT EXISTS idx_problems_next_review_at ON problems(datetime(next_review))`,
+	)},
+}
+
+// SchemaVersion is the version a fully migrated database is at.
+var SchemaVersion = migrations[len(migrations)-1].version
+
+func execAll(statements ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, stmt := range statements {
+			if _, err := tx.Exec(stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// addColumns adds each column definition ("name TYPE ...") to table,
+// skipping columns that already exist.
+func addColumns(table string, columns ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		existing, err := tableColumns(tx, table)
+		if err != nil {
+			return err
+		}
+		for _, def := range columns {
+			var name string
+			fmt.Sscan(def, &name)
+			if existing[name] {
+				continue
+			}
+			if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, def)); err != nil {
+				return fmt.Errorf("adding %s.%s: %w", table, name, err)
+			}
+		}
+		return nil
+	}
+}
+
+func tableColumns(tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, typ        string
+			dflt             sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
+
+// backfillLegacyReviews gives problems reviewed before review history was
+// kept a single synthetic review, so their history is not empty.
+func backfillLegacyReviews(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		INSERT INTO reviews (problem_id, quality, reviewed_at, notes, interval_snapshot, ease_factor_snapshot)
+		SELECT p.id, 3, p.last_reviewed, ?, p.interval, p.ease_factor
+		FROM problems p
+		WHERE p.last_reviewed > '1970-01-01'
+		AND NOT EXISTS (SELECT 1 FROM reviews r WHERE r.problem_id = p.id)`,
+		LegacyReviewNote,
+	)
+	return err
+}
+
+// migrate applies every pending migration and returns the ones it applied.
+func migrate(db *sql.DB) ([]Migration, error) {
+	err := retryBusy(func() error {
+		_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		);
+		`)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := suspendSearchIndex(db); err != nil {
+		return nil, fmt.Errorf("suspending the search index: %w", err)
+	}
+
+	status, err := migrationStatus(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for i, m := range migrations {
+		if !status[i].AppliedAt.IsZero() {
+			continue
+		}
+		at, err := applyMigration(db, m)
+		if errors.Is(err, ErrSearchUnavailable) {
+			// Stays pending until recall is built with FTS5.
+			continue
+		}
+		if err != nil {
+			return applied, fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+		if !at.IsZero() {
+			applied = append(applied, Migration{Version: m.version, Name: m.name, AppliedAt: at})
+		}
+	}
+	return applied, nil
+}
+
+func applyMigration(db *sql.DB, m migration) (at time.Time, err error) {
+	err = retryBusy(func() error {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		// Another process may have applied it while we waited for the lock.
+		var done int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE version = ?`, m.version).Scan(&done); err != nil || done > 0 {
+			return err
+		}
+		if err := m.up(tx); err != nil {
+			return err
+		}
+		at = time.Now()
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`, m.version, m.name, at); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+	return at, err
+}
+
+func migrationStatus(db *sql.DB) ([]Migration, error) {
+	rows, err := db.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	appliedAt := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		appliedAt[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	status := make([]Migration, len(migrations))
+	for i, m := range migrations {
+		status[i] = Migration{Version: m.version, Name: m.name, AppliedAt: appliedAt[m.version]}
+	}
+	return status, nil
+}
+
+// Migrator is implemented by repositories with a versioned schema.
+type Migrator interface {
+	Migrate() ([]Migration, error)
+	Migrations() ([]Migration, error)
+}
+
+// Migrate applies any pending schema migrations and returns the ones it
+// applied. NewStore already migrates, so this is normally a no-op.
+func (s *Store) Migrate() ([]Migration, error) {
+	return migrate(s.db)
+}
+
+// Migrations lists every known schema migration and when it was applied.
+func (s *Store) Migrations() ([]Migration, error) {
+	return migrationStatus(s.db)
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultCollection names the collection stored in ~/.recall/recall.db.
+const DefaultCollection = "default"
+
+var collectionName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// DataDir returns the directory recall keeps its data in, ~/.recall.
+func DataDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".recall"), nil
+}
+
+// ValidateCollection reports whether name can be used as a collection name.
+func ValidateCollection(name string) error {
+	if !collectionName.MatchString(name) {
+		return fmt.Errorf("invalid collection name %q (use letters, digits, - and _)", name)
+	}
+	return nil
+}
+
+// CollectionPath returns the database file of the named collection.
+// Collections other than the default live in ~/.recall/collections.
+func CollectionPath(name string) (string, error) {
+	if err := ValidateCollection(name); err != nil {
+		return "", err
+	}
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	if name == DefaultCollection {
+		return filepath.Join(dir, "recall.db"), nil
+	}
+	return filepath.Join(dir, "collections", name+".db"), nil
+}
+
+// Collections lists the collections that exist, the default one first.
+func Collections() ([]string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return nil, err
+	}
+	names := []string{DefaultCollection}
+	files, err := filepath.Glob(filepath.Join(dir, "collections", "*.db"))
+	if err != nil {
+		return nil, err
+	}
+	var others []string
+	for _, f := range files {
+		name := strings.TrimSuffix(filepath.Base(f), ".db")
+		if name != DefaultCollection && ValidateCollection(name) == nil {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	return append(names, others...), nil
+}
+
+// CollectionExists reports whether the named collection has a database.
+func CollectionExists(name string) (bool, error) {
+	path, err := CollectionPath(name)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// DeleteCollection removes the database of the named collection. The
+// default collection cannot be deleted.
+func DeleteCollection(name string) error {
+	if name == DefaultCollection {
+		return fmt.Errorf("the default collection cannot be deleted")
+	}
+	path, err := CollectionPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no collection named %q", name)
+		}
+		return err
+	}
+	// SQLite side files, if a journal was left behind
+	for _, suffix := range []string{"-journal", "-wal", "-shm"} {
+		os.Remove(path + suffix)
+	}
+	return nil
+}
+
+// CountProblems counts the problems in the database at path. It opens the
+// database read-only and skips migrations, so looking at a collection never
+// changes it.
+func CountProblems(path string) (int, error) {
+	params := url.Values{}
+	params.Set("mode", "ro")
+	params.Set("_busy_timeout", fmt.Sprint(busyTimeout.Milliseconds()))
+	uri := &url.URL{Scheme: "file", Path: path, RawQuery: params.Encode()}
+	db, err := sql.Open("sqlite3", uri.String())
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM problems").Scan(&count)
+	return count, err
+}
@@ -0,0 +1,120 @@
+package db_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/LavenderBridge/spaced-repetition/internal/query"
+)
+
+// TestQueryAgreement runs the same queries through the SQL store and
+// through query.Match and query.Sort in the MemoryStore, which must return
+// the same problems in the same order.
+func TestQueryAgreement(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	cal := clock.Calendar{Location: tokyo, DayStartHour: 4}
+
+	store, err := db.NewStore(filepath.Join(t.TempDir(), "recall.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	repos := map[string]db.Repository{"Store": store, "MemoryStore": db.NewMemoryStore()}
+
+	// Times alternate between UTC and Tokyo offsets, so that their text
+	// and their instants sort differently.
+	zones := []*time.Location{time.UTC, tokyo}
+	for i := range 24 {
+		loc := zones[i%2]
+		next := now.Add(time.Duration(i%9-3) * 11 * time.Hour).In(loc)
+		p := models.Problem{
+			Name:         fmt.Sprintf("Problem %02d", 24-i),
+			Difficulty:   1 + i%5,
+			Interval:     i % 7,
+			EaseFactor:   1.3 + float64(i%4)*0.4,
+			Lapses:       i % 3,
+			Box:          1 + i%6,
+			State:        []models.State{models.StateNew, models.StateLearning, models.StateReview, models.StateRelearning, models.StateReview}[i%5],
+			LastReviewed: next.AddDate(0, 0, -(i % 7)).Add(-time.Duration(i) * time.Hour).In(zones[(i+1)%2]),
+			NextReview:   next,
+			Suspended:    i%11 == 0,
+		}
+		if i%4 == 0 {
+			p.Tags = []models.Tag{{Name: "dp"}}
+		}
+		if i%6 == 0 {
+			p.Tags = append(p.Tags, models.Tag{Name: "Leech"})
+		}
+		for name, repo := range repos {
+			if _, err := repo.AddProblem(p); err != nil {
+				t.Fatalf("%s: AddProblem: %v", name, err)
+			}
+		}
+	}
+	for _, repo := range repos {
+		repo.SetClock(clock.Fixed(now))
+		repo.SetCalendar(cal)
+	}
+
+	for _, filter := range []string{
+		"", "is:due", "is:new OR is:learning", "-is:review", "is:leech", "is:suspended",
+		"tag:DP", "tag:dp -tag:leech", "name:1", `name:"problem 2" OR diff:5`,
+		"diff:>=4 (due:<2 OR reviewed:>3)", "ivl:2..4", "ease:<2", "lapses:!=1 box:<=3",
+		"due:0", "due:<0", "due:-1..1", "reviewed:<=1", "reviewed:2d..1w",
+	} {
+		expr, err := query.Parse(filter)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", filter, err)
+		}
+		for _, key := range query.SortKeys {
+			if key == query.SortRecall {
+				continue
+			}
+			for _, reverse := range []bool{false, true} {
+				q := query.Query{Filter: expr, Sort: key, Reverse: reverse, LeechThreshold: 2}
+				got := map[string]string{}
+				for name, repo := range repos {
+					problems, err := repo.QueryProblems(q)
+					if err != nil {
+						t.Fatalf("%s: QueryProblems(%q): %v", name, filter, err)
+					}
+					var ids []int
+					for _, p := range problems {
+						ids = append(ids, p.ID)
+					}
+					got[name] = fmt.Sprint(ids)
+				}
+				if got["Store"] != got["MemoryStore"] {
+					t.Errorf("%q sorted by %s (reverse %v): Store got %s, MemoryStore %s", filter, key, reverse, got["Store"], got["MemoryStore"])
+				}
+			}
+		}
+	}
+
+	for _, dueOnly := range []bool{false, true} {
+		got := map[string]string{}
+		for name, repo := range repos {
+			problems, err := repo.ListProblems(dueOnly)
+			if err != nil {
+				t.Fatalf("%s: ListProblems: %v", name, err)
+			}
+			var ids []int
+			for _, p := range problems {
+				ids = append(ids, p.ID)
+			}
+			got[name] = fmt.Sprint(ids)
+		}
+		if got["Store"] != got["MemoryStore"] {
+			t.Errorf("ListProblems(%v): Store got %s, MemoryStore %s", dueOnly, got["Store"], got["MemoryStore"])
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/LavenderBridge/spaced-repetition/internal/query"
+)
+
+// ErrNotFound is returned when a problem or review does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Repository stores problems, their review history, settings and presets.
+// Store keeps them in SQLite; MemoryStore keeps them in memory, for tests
+// and for embedding recall without a database file.
+type Repository interface {
+	Close() error
+
+	// SetClock replaces the clock used to decide what is due "now".
+	SetClock(c clock.Clock)
+	// SetCalendar replaces the calendar used to decide what "today" is.
+	SetCalendar(c clock.Calendar)
+	// Calendar returns the calendar used to decide what "today" is.
+	Calendar() clock.Calendar
+
+	// AddProblem stores a new problem with its tags and returns the ID it
+	// was given. Names are unique.
+	AddProblem(p models.Problem) (int, error)
+	// GetProblem returns the problem with exactly the given name.
+	GetProblem(name string) (*models.Problem, error)
+	// GetProblemByID returns the problem with the given ID.
+	GetProblemByID(id int) (*models.Problem, error)
+	// Search returns the problems matching every word of query in their
+	// name, notes, tags or review notes, best match first, with a snippet
+	// whose hits are wrapped in HighlightStart and HighlightEnd. A limit of
+	// 0 returns every match.
+	Search(query string, limit int) ([]models.SearchResult, error)
+	// FindProblems returns the problems query refers to, ordered by name:
+	// the one with that ID, exact name, URL slug or name ignoring case, or
+	// else every problem whose name matches query loosely. More than one
+	// result means the query is ambiguous; none means nothing matched.
+	FindProblems(query string) ([]models.Problem, error)
+	// UpdateProblem saves the scheduling state of a problem.
+	UpdateProblem(p models.Problem) error
+	// UpdateProblemDetails saves the name, URL, notes, difficulty,
+	// suspension and tags of a problem, replacing its previous tags.
+	UpdateProblemDetails(p models.Problem) error
+	// SaveProblem saves both the details and the scheduling state of a
+	// problem, all or nothing.
+	SaveProblem(p models.Problem) error
+	// RescheduleProblems saves the scheduling state of every problem, all
+	// or nothing.
+	RescheduleProblems(problems []models.Problem) error
+	// DeleteProblem removes a problem with its tags and reviews.
+	DeleteProblem(id int) error
+	// ListProblems returns all problems, or only those due now, ordered by
+	// next review.
+	ListProblems(dueOnly bool) ([]models.Problem, error)
+	// QueryProblems returns the problems matching q.Filter with their tags,
+	// sorted by q.Sort and cut to q.Limit. Sorting by recall fails with
+	// query.ErrRecallSort.
+	QueryProblems(q query.Query) ([]models.Problem, error)
+	// CountDueOn returns how many active problems are scheduled on the
+	// study day containing t.
+	CountDueOn(t time.Time) (int, error)
+	// AddTag links an extra tag to a problem, keeping its existing tags.
+	AddTag(problemID int, tagName string) error
+	// SetSuspended suspends or unsuspends a problem.
+	SetSuspended(problemID int, suspended bool) error
+
+	AddReview(r models.Review) error
+	// RecordReview saves the new scheduling state and suspension of a
+	// reviewed problem, adds any of its tags it does not have yet, such as
+	// the leech tag, and adds the review to its history, all or nothing.
+	RecordReview(p models.Problem, r models.Review) error
+	// GetLastReview returns the most recent review of a problem.
+	GetLastReview(problemID int) (*models.Review, error)
+	// ListReviews returns the full review log ordered by problem and
+	// review time.
+	ListReviews() ([]models.Review, error)
+	GetReviewStats() (*models.ReviewStats, error)
+
+	// GetSetting returns the value stored under key, or "" if it is unset.
+	GetSetting(key string) (string, error)
+	// SetSetting stores value under key, replacing any previous value.
+	SetSetting(key, value string) error
+
+	// ListPresets returns every scheduler preset ordered by name.
+	ListPresets() ([]models.Preset, error)
+	// SavePreset creates a preset or replaces the one with the same name.
+	SavePreset(p models.Preset) error
+	// DeletePreset removes a preset and unassigns it from its tags.
+	DeletePreset(name string) error
+	// AssignPreset makes problems tagged tagName use the named preset,
+	// creating the tag if needed. An empty preset unassigns it.
+	AssignPreset(tagName, preset string) error
+	// PresetAssignments returns the preset assigned to each tag that has one.
+	PresetAssignments() (map[string]string, error)
+}
+
+var (
+	_ Repository = (*Store)(nil)
+	_ Repository = (*MemoryStore)(nil)
+)
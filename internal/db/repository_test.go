@@ -0,0 +1,26 @@
+package db_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/db/repotest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	if err := repotest.Check(db.NewMemoryStore()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStore(t *testing.T) {
+	store, err := db.NewStore(filepath.Join(t.TempDir(), "recall.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := repotest.Check(store); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,479 @@
+// Package repotest checks that a db.Repository implementation behaves like
+// the SQLite store, in the manner of testing/fstest.TestFS.
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+	"github.com/LavenderBridge/spaced-repetition/internal/db"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/LavenderBridge/spaced-repetition/internal/query"
+)
+
+// Check exercises every method of repo, which must be empty, and returns
+// an error describing each behaviour that differs from the contract. It
+// leaves data behind, so use a throwaway repository:
+//
+//	if err := repotest.Check(db.NewMemoryStore()); err != nil {
+//		t.Fatal(err)
+//	}
+func Check(repo db.Repository) error {
+	c := &checker{repo: repo}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo.SetClock(clock.Fixed(now))
+	repo.SetCalendar(clock.Calendar{Location: time.UTC, DayStartHour: 4})
+
+	c.problems(now)
+	c.due(now)
+	c.reviews(now)
+	c.settings()
+	c.presets()
+	c.find(now)
+	c.search(now)
+	c.delete(now)
+	return errors.Join(c.errs...)
+}
+
+type checker struct {
+	repo db.Repository
+	errs []error
+}
+
+func (c *checker) errorf(format string, args ...any) {
+	c.errs = append(c.errs, fmt.Errorf(format, args...))
+}
+
+// ok records err and reports whether there was none.
+func (c *checker) ok(what string, err error) bool {
+	if err != nil {
+		c.errorf("%s: %v", what, err)
+		return false
+	}
+	return true
+}
+
+func (c *checker) get(name string) *models.Problem {
+	p, err := c.repo.GetProblem(name)
+	if !c.ok("GetProblem("+name+")", err) {
+		return nil
+	}
+	return p
+}
+
+// add adds p and checks that AddProblem returns the ID it is stored under.
+func (c *checker) add(p models.Problem) bool {
+	id, err := c.repo.AddProblem(p)
+	if !c.ok("AddProblem("+p.Name+")", err) {
+		return false
+	}
+	if added := c.get(p.Name); added != nil && added.ID != id {
+		c.errorf("AddProblem(%s): returned ID %d, want %d", p.Name, id, added.ID)
+	}
+	return true
+}
+
+func tagNames(p *models.Problem) []string {
+	var names []string
+	for _, t := range p.Tags {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func problem(name string, next time.Time, state models.State, tags ...string) models.Problem {
+	p := models.Problem{
+		Name:         name,
+		Difficulty:   3,
+		Interval:     1,
+		EaseFactor:   2.5,
+		LastReviewed: next.AddDate(0, 0, -1),
+		NextReview:   next,
+		State:        state,
+	}
+	for _, t := range tags {
+		p.Tags = append(p.Tags, models.Tag{Name: t})
+	}
+	return p
+}
+
+func (c *checker) problems(now time.Time) {
+	if !c.add(problem("Two Sum", now, models.StateReview, "array", "hash")) {
+		return
+	}
+	if _, err := c.repo.AddProblem(problem("Two Sum", now, models.StateReview)); err == nil {
+		c.errorf("AddProblem: duplicate name was accepted")
+	}
+	if _, err := c.repo.GetProblem("Missing"); !errors.Is(err, db.ErrNotFound) {
+		c.errorf("GetProblem(Missing): got error %v, want ErrNotFound", err)
+	}
+
+	p := c.get("Two Sum")
+	if p == nil {
+		return
+	}
+	if p.ID == 0 || p.Difficulty != 3 || p.EaseFactor != 2.5 || !p.NextReview.Equal(now) {
+		c.errorf("GetProblem: got %+v, want the added problem", *p)
+	}
+	if got := fmt.Sprint(tagNames(p)); got != "[array hash]" {
+		c.errorf("GetProblem: got tags %s, want [array hash]", got)
+	}
+	if q, err := c.repo.GetProblemByID(p.ID); c.ok("GetProblemByID", err) && (q.Name != p.Name || len(q.Tags) != 2) {
+		c.errorf("GetProblemByID: got %q with %d tags, want %q with 2", q.Name, len(q.Tags), p.Name)
+	}
+	if _, err := c.repo.GetProblemByID(-1); !errors.Is(err, db.ErrNotFound) {
+		c.errorf("GetProblemByID(-1): got error %v, want ErrNotFound", err)
+	}
+
+	p.Interval, p.EaseFactor, p.State, p.Lapses, p.Box = 6, 2.6, models.StateReview, 2, 3
+	p.NextReview = now.AddDate(0, 0, 6)
+	c.ok("UpdateProblem", c.repo.UpdateProblem(*p))
+	if q := c.get("Two Sum"); q != nil && (q.Interval != 6 || q.EaseFactor != 2.6 || q.Lapses != 2 || q.Box != 3 || !q.NextReview.Equal(p.NextReview)) {
+		c.errorf("UpdateProblem: got %+v, want interval 6, ease 2.6, 2 lapses, box 3", *q)
+	}
+
+	p.Name, p.Notes = "Two Sum II", "sorted input"
+	p.Tags = []models.Tag{{Name: "two-pointers"}}
+	c.ok("UpdateProblemDetails", c.repo.UpdateProblemDetails(*p))
+	if q := c.get("Two Sum II"); q != nil {
+		if q.Notes != "sorted input" || fmt.Sprint(tagNames(q)) != "[two-pointers]" {
+			c.errorf("UpdateProblemDetails: got notes %q and tags %v, want the new ones only", q.Notes, tagNames(q))
+		}
+	}
+
+	p.Interval = 9
+	p.Difficulty = 4
+	c.add(problem("Placeholder", now, models.StateNew))
+	p.Name = "Placeholder"
+	if c.repo.UpdateProblemDetails(*p) == nil {
+		c.errorf("UpdateProblemDetails: renaming onto a duplicate name was accepted")
+	}
+	if c.repo.SaveProblem(*p) == nil {
+		c.errorf("SaveProblem: renaming onto a duplicate name was accepted")
+	}
+	if q := c.get("Two Sum II"); q != nil && (q.Interval != 6 || q.Difficulty != 3) {
+		c.errorf("SaveProblem: a failed save changed the problem to %+v", *q)
+	}
+	p.Name = "Two Sum II"
+	c.ok("SaveProblem", c.repo.SaveProblem(*p))
+	if q := c.get("Two Sum II"); q != nil && (q.Interval != 9 || q.Difficulty != 4) {
+		c.errorf("SaveProblem: got interval %d and difficulty %d, want 9 and 4", q.Interval, q.Difficulty)
+	}
+	if placeholder := c.get("Placeholder"); placeholder != nil {
+		c.ok("DeleteProblem", c.repo.DeleteProblem(placeholder.ID))
+	}
+	p.Difficulty = 3
+	c.ok("SaveProblem", c.repo.SaveProblem(*p))
+
+	c.ok("AddTag", c.repo.AddTag(p.ID, "Leech")) // is:leech matches it in any case
+	if q := c.get("Two Sum II"); q != nil && !q.HasTag("leech") {
+		c.errorf("AddTag: tag leech missing, got %v", tagNames(q))
+	}
+}
+
+func (c *checker) due(now time.Time) {
+	later := now.Add(3 * time.Hour) // Still today
+	added := []models.Problem{
+		problem("Due Yesterday", now.AddDate(0, 0, -1), models.StateReview),
+		problem("Due Later Today", later, models.StateReview),
+		problem("Learning Later Today", later, models.StateLearning),
+		problem("Learning Now", now.Add(-time.Minute), models.StateLearning),
+		problem("Due Tomorrow", now.AddDate(0, 0, 1), models.StateReview),
+		problem("Suspended", now.AddDate(0, 0, -2), models.StateReview),
+	}
+	for _, p := range added {
+		if !c.add(p) {
+			return
+		}
+	}
+	if s := c.get("Suspended"); s != nil {
+		c.ok("SetSuspended", c.repo.SetSuspended(s.ID, true))
+	}
+
+	due, err := c.repo.ListProblems(true)
+	if !c.ok("ListProblems(true)", err) {
+		return
+	}
+	var names []string
+	for _, p := range due {
+		names = append(names, p.Name)
+	}
+	want := "[Due Yesterday Learning Now Due Later Today]"
+	if got := fmt.Sprint(names); got != want {
+		c.errorf("ListProblems(true): got %s, want %s", got, want)
+	}
+
+	all, err := c.repo.ListProblems(false)
+	if c.ok("ListProblems(false)", err) {
+		if len(all) != 7 {
+			c.errorf("ListProblems(false): got %d problems, want 7", len(all))
+		}
+		for i := 1; i < len(all); i++ {
+			if all[i].NextReview.Before(all[i-1].NextReview) {
+				c.errorf("ListProblems(false): not ordered by next review")
+				break
+			}
+		}
+	}
+
+	// Two Sum II (in 6 days) and Due Tomorrow fall on their own days.
+	if n, err := c.repo.CountDueOn(now.AddDate(0, 0, 1)); c.ok("CountDueOn", err) && n != 1 {
+		c.errorf("CountDueOn(tomorrow): got %d, want 1", n)
+	}
+	if n, err := c.repo.CountDueOn(now); c.ok("CountDueOn", err) && n != 3 {
+		c.errorf("CountDueOn(today): got %d, want 3 (overdue and suspended problems excluded)", n)
+	}
+	c.query()
+
+	for i := range added {
+		added[i].NextReview = now.AddDate(0, 1, 0)
+		if p := c.get(added[i].Name); p != nil {
+			added[i].ID = p.ID
+		}
+	}
+	c.ok("RescheduleProblems", c.repo.RescheduleProblems(added))
+	if due, err := c.repo.ListProblems(true); c.ok("ListProblems(true)", err) && len(due) != 0 {
+		c.errorf("RescheduleProblems: %d problems still due, want 0", len(due))
+	}
+}
+
+// query checks QueryProblems against the problems added by due, with Two
+// Sum II from problems due in 6 days.
+func (c *checker) query() {
+	for _, tt := range []struct {
+		filter string
+		q      query.Query
+		want   string
+	}{
+		{"is:due", query.Query{}, "[Due Yesterday Learning Now Due Later Today]"},
+		{"tag:TWO-POINTERS", query.Query{}, "[Two Sum II]"},
+		{"name:due -is:suspended", query.Query{}, "[Due Yesterday Due Later Today Due Tomorrow]"},
+		{"due:<=1d is:review", query.Query{Sort: "name"}, "[Due Later Today Due Tomorrow Due Yesterday Suspended]"},
+		{"reviewed:<2d", query.Query{Sort: "name"}, "[Due Later Today Due Tomorrow Learning Later Today Learning Now Two Sum II]"},
+		{"(is:learning OR tag:two-pointers) ease:>2.5", query.Query{}, "[Two Sum II]"},
+		{"is:learning OR tag:two-pointers", query.Query{Sort: "id", Reverse: true, Limit: 2}, "[Learning Now Learning Later Today]"},
+		{"diff:2..3 ivl:!=1", query.Query{}, "[Two Sum II]"},
+		{"is:leech", query.Query{}, "[Two Sum II]"},
+		{"lapses:>=2 -tag:leech", query.Query{}, "[]"},
+	} {
+		filter, err := query.Parse(tt.filter)
+		if err != nil {
+			c.errorf("query.Parse(%q): %v", tt.filter, err)
+			continue
+		}
+		tt.q.Filter = filter
+		problems, err := c.repo.QueryProblems(tt.q)
+		if !c.ok("QueryProblems("+tt.filter+")", err) {
+			continue
+		}
+		var names []string
+		for _, p := range problems {
+			names = append(names, p.Name)
+		}
+		if got := fmt.Sprint(names); got != tt.want {
+			c.errorf("QueryProblems(%q): got %s, want %s", tt.filter, got, tt.want)
+		}
+	}
+	if _, err := c.repo.QueryProblems(query.Query{Sort: query.SortRecall}); !errors.Is(err, query.ErrRecallSort) {
+		c.errorf("QueryProblems sorted by recall: got error %v, want ErrRecallSort", err)
+	}
+}
+
+func (c *checker) reviews(now time.Time) {
+	p := c.get("Two Sum II")
+	if p == nil {
+		return
+	}
+	if _, err := c.repo.GetLastReview(p.ID); !errors.Is(err, db.ErrNotFound) {
+		c.errorf("GetLastReview before any review: got error %v, want ErrNotFound", err)
+	}
+	for i, q := range []int{5, 2} {
+		r := models.Review{ProblemID: p.ID, Quality: q, ReviewedAt: now.AddDate(0, 0, -10*(2-i)), Notes: fmt.Sprint("review ", i)}
+		c.ok("AddReview", c.repo.AddReview(r))
+	}
+	p.Interval = 12
+	p.Suspended = true
+	p.Tags = append(p.Tags, models.Tag{Name: "relearn"})
+	c.ok("RecordReview", c.repo.RecordReview(*p, models.Review{ProblemID: p.ID, Quality: 4, ReviewedAt: now, Notes: "review 2"}))
+	if q := c.get("Two Sum II"); q != nil && (q.Interval != 12 || !q.Suspended || !q.HasTag("relearn") || !q.HasTag("leech")) {
+		c.errorf("RecordReview: got interval %d, suspended %v and tags %v, want 12, true and relearn added", q.Interval, q.Suspended, tagNames(q))
+	}
+	c.ok("SetSuspended", c.repo.SetSuspended(p.ID, false))
+	last, err := c.repo.GetLastReview(p.ID)
+	if c.ok("GetLastReview", err) && (last.Quality != 4 || last.Notes != "review 2") {
+		c.errorf("GetLastReview: got quality %d (%q), want the latest review", last.Quality, last.Notes)
+	}
+
+	reviews, err := c.repo.ListReviews()
+	if c.ok("ListReviews", err) {
+		if len(reviews) != 3 {
+			c.errorf("ListReviews: got %d reviews, want 3", len(reviews))
+		}
+		for i := 1; i < len(reviews); i++ {
+			if reviews[i].ReviewedAt.Before(reviews[i-1].ReviewedAt) {
+				c.errorf("ListReviews: not ordered by review time")
+				break
+			}
+		}
+	}
+
+	stats, err := c.repo.GetReviewStats()
+	if c.ok("GetReviewStats", err) {
+		if stats.TotalReviews != 3 || stats.ReviewsLast7Days != 1 || stats.AverageQuality < 3.66 || stats.AverageQuality > 3.67 {
+			c.errorf("GetReviewStats: got %+v, want 3 reviews, 1 this week, average 3.67", *stats)
+		}
+		if stats.CountByDifficulty[3] != 7 {
+			c.errorf("GetReviewStats: got %d problems of difficulty 3, want 7", stats.CountByDifficulty[3])
+		}
+	}
+}
+
+func (c *checker) settings() {
+	if v, err := c.repo.GetSetting("scheduler"); c.ok("GetSetting", err) && v != "" {
+		c.errorf("GetSetting(unset): got %q, want empty", v)
+	}
+	c.ok("SetSetting", c.repo.SetSetting("scheduler", "fsrs"))
+	c.ok("SetSetting", c.repo.SetSetting("scheduler", "sm2"))
+	if v, err := c.repo.GetSetting("scheduler"); c.ok("GetSetting", err) && v != "sm2" {
+		c.errorf("GetSetting: got %q, want sm2", v)
+	}
+}
+
+func (c *checker) presets() {
+	c.ok("SavePreset", c.repo.SavePreset(models.Preset{Name: "interview", MaxInterval: 30}))
+	c.ok("SavePreset", c.repo.SavePreset(models.Preset{Name: "interview", MaxInterval: 14, TargetRetention: 0.95}))
+	c.ok("SavePreset", c.repo.SavePreset(models.Preset{Name: "casual"}))
+
+	presets, err := c.repo.ListPresets()
+	if c.ok("ListPresets", err) {
+		if len(presets) != 2 || presets[0].Name != "casual" || presets[1].MaxInterval != 14 {
+			c.errorf("ListPresets: got %+v, want casual then the updated interview preset", presets)
+		}
+	}
+
+	c.ok("AssignPreset", c.repo.AssignPreset("two-pointers", "interview"))
+	c.ok("AssignPreset", c.repo.AssignPreset("graphs", "interview"))
+	c.ok("AssignPreset", c.repo.AssignPreset("dp", "casual"))
+	c.ok("AssignPreset", c.repo.AssignPreset("dp", ""))
+	if p := c.get("Two Sum II"); p != nil {
+		for _, t := range p.Tags {
+			if t.Name == "two-pointers" && t.Preset != "interview" {
+				c.errorf("AssignPreset: problem tag has preset %q, want interview", t.Preset)
+			}
+		}
+	}
+
+	c.ok("DeletePreset", c.repo.DeletePreset("interview"))
+	assignments, err := c.repo.PresetAssignments()
+	if c.ok("PresetAssignments", err) && len(assignments) != 0 {
+		c.errorf("PresetAssignments after deleting and unassigning: got %v, want none", assignments)
+	}
+}
+
+func (c *checker) find(now time.Time) {
+	later := now.AddDate(0, 1, 0)
+	parens := problem("Valid Parentheses", later, models.StateReview, "stack")
+	parens.URL = "https://leetcode.com/problems/valid-parentheses/"
+	for _, p := range []models.Problem{parens, problem("Valid Anagram", later, models.StateReview), problem("LRU Cache", later, models.StateReview)} {
+		c.add(p)
+	}
+	lru := c.get("LRU Cache")
+	if lru == nil {
+		return
+	}
+
+	for _, tt := range []struct {
+		query string
+		want  string
+	}{
+		{fmt.Sprint(lru.ID), "[LRU Cache]"},
+		{"Valid Anagram", "[Valid Anagram]"},
+		{"valid anagram", "[Valid Anagram]"},
+		{"valid-parentheses", "[Valid Parentheses]"},
+		{"https://leetcode.com/problems/valid-parentheses/description/", "[Valid Parentheses]"},
+		{"VALID", "[Valid Anagram Valid Parentheses]"},
+		{"lrucch", "[LRU Cache]"},
+		{"no such problem", "[]"},
+	} {
+		found, err := c.repo.FindProblems(tt.query)
+		if !c.ok("FindProblems("+tt.query+")", err) {
+			continue
+		}
+		var names []string
+		for _, p := range found {
+			names = append(names, p.Name)
+		}
+		if got := fmt.Sprint(names); got != tt.want {
+			c.errorf("FindProblems(%q): got %s, want %s", tt.query, got, tt.want)
+		}
+	}
+	if found, err := c.repo.FindProblems("valid-parentheses"); err == nil && len(found) == 1 && fmt.Sprint(tagNames(&found[0])) != "[stack]" {
+		c.errorf("FindProblems: got tags %v, want [stack]", tagNames(&found[0]))
+	}
+}
+
+// search checks full-text search over the problems added by find.
+func (c *checker) search(now time.Time) {
+	lru := c.get("LRU Cache")
+	if lru == nil {
+		return
+	}
+	c.ok("AddReview", c.repo.AddReview(models.Review{ProblemID: lru.ID, Quality: 4, ReviewedAt: now, Notes: "Keep a monotonic stack of keys"}))
+
+	for _, tt := range []struct {
+		query string
+		want  string
+	}{
+		{"stack", "[Valid Parentheses LRU Cache]"}, // A tag outranks a review note
+		{"monotonic stack", "[LRU Cache]"},
+		{"parenth", "[Valid Parentheses]"},
+		{"no-such-word", "[]"},
+	} {
+		results, err := c.repo.Search(tt.query, 0)
+		if !c.ok("Search("+tt.query+")", err) {
+			continue
+		}
+		var names []string
+		for _, r := range results {
+			names = append(names, r.Problem.Name)
+		}
+		if got := fmt.Sprint(names); got != tt.want {
+			c.errorf("Search(%q): got %s, want %s", tt.query, got, tt.want)
+		}
+	}
+	if results, err := c.repo.Search("monotonic", 1); c.ok("Search(monotonic)", err) && len(results) == 1 {
+		if want := db.HighlightStart + "monotonic" + db.HighlightEnd; !strings.Contains(results[0].Snippet, want) {
+			c.errorf("Search(monotonic): got snippet %q, want it to contain %q", results[0].Snippet, want)
+		}
+		if len(results[0].Problem.Tags) != 0 || results[0].Problem.Name != "LRU Cache" {
+			c.errorf("Search(monotonic): got %+v, want LRU Cache", results[0].Problem)
+		}
+	}
+}
+
+func (c *checker) delete(now time.Time) {
+	p := c.get("Two Sum II")
+	if p == nil {
+		return
+	}
+	c.ok("DeleteProblem", c.repo.DeleteProblem(p.ID))
+	if _, err := c.repo.GetProblem("Two Sum II"); !errors.Is(err, db.ErrNotFound) {
+		c.errorf("GetProblem after DeleteProblem: got error %v, want ErrNotFound", err)
+	}
+	if _, err := c.repo.GetLastReview(p.ID); !errors.Is(err, db.ErrNotFound) {
+		c.errorf("GetLastReview after DeleteProblem: got error %v, want ErrNotFound", err)
+	}
+	if reviews, err := c.repo.ListReviews(); c.ok("ListReviews", err) {
+		for _, r := range reviews {
+			if r.ProblemID == p.ID {
+				c.errorf("ListReviews after DeleteProblem: got review %d of the deleted problem", r.ID)
+			}
+		}
+	}
+	if results, err := c.repo.Search("sorted", 0); err == nil && len(results) != 0 {
+		c.errorf("Search after DeleteProblem: got %d results, want 0", len(results))
+	}
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// matchProblems returns the candidates that query refers to, trying in
+// turn: the ID, the exact name, the name ignoring case, the URL slug (or
+// the name written as one, e.g. "two-sum" for "Two Sum"), the name
+// containing query, and finally the name containing the letters of query
+// in order. The first way that matches anything wins, so a more precise
+// match is never made ambiguous by a looser one. Candidates only need
+// their ID, name and URL.
+func matchProblems(query string, candidates []models.Problem) []models.Problem {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+	slug := slugify(query)
+	if strings.Contains(query, "://") {
+		slug = urlSlug(query)
+	}
+	lower := strings.ToLower(query)
+
+	id, err := strconv.Atoi(query)
+	isID := err == nil
+	matchers := []func(p models.Problem) bool{
+		func(p models.Problem) bool { return isID && p.ID == id },
+		func(p models.Problem) bool { return p.Name == query },
+		func(p models.Problem) bool { return strings.EqualFold(p.Name, query) },
+		func(p models.Problem) bool {
+			return slug != "" && (slugify(p.Name) == slug || (p.URL != "" && urlSlug(p.URL) == slug))
+		},
+		func(p models.Problem) bool { return strings.Contains(strings.ToLower(p.Name), lower) },
+		func(p models.Problem) bool { return isSubsequence(lower, strings.ToLower(p.Name)) },
+	}
+	for _, match := range matchers {
+		var found []models.Problem
+		for _, p := range candidates {
+			if match(p) {
+				found = append(found, p)
+			}
+		}
+		if len(found) > 0 {
+			sortByName(found)
+			return found
+		}
+	}
+	return nil
+}
+
+func sortByName(problems []models.Problem) {
+	slices.SortFunc(problems, func(a, b models.Problem) int {
+		if c := strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); c != 0 {
+			return c
+		}
+		return a.ID - b.ID
+	})
+}
+
+// slugify writes a name the way LeetCode URLs do: lower case, with runs of
+// anything but letters and digits turned into single dashes.
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// urlSlug returns the problem slug of a URL such as
+// https://leetcode.com/problems/two-sum/description: the path segment
+// after "problems", or else the last one.
+func urlSlug(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	var segments []string
+	for _, s := range strings.Split(u.Path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	if len(segments) == 0 {
+		return ""
+	}
+	if i := slices.Index(segments, "problems"); i >= 0 && i+1 < len(segments) {
+		return slugify(segments[i+1])
+	}
+	return slugify(segments[len(segments)-1])
+}
+
+// isSubsequence reports whether the letters and digits of query appear in
+// s in order, e.g. "lrucache" in "lru cache" or "tsum" in "two sum".
+func isSubsequence(query, s string) bool {
+	rest := s
+	matched := false
+	for _, r := range query {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		i := strings.IndexRune(rest, r)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(string(r)):]
+		matched = true
+	}
+	return matched
+}
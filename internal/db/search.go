@@ -0,0 +1,346 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// ErrSearchUnavailable is returned by the create_search_index migration when
+// SQLite was built without FTS5, which leaves it pending until it is. Search
+// falls back to matching with LIKE meanwhile.
+var ErrSearchUnavailable = errors.New("full-text search is not available in this build (rebuild recall with -tags sqlite_fts5)")
+
+// HighlightStart and HighlightEnd surround the matching terms in search
+// snippets, for the caller to render as it likes.
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+// searchWeights ranks a hit in the name above one in the notes, tags and
+// review notes, in that order. They are the bm25 column weights of
+// problems_fts.
+const searchWeights = "10.0, 5.0, 3.0, 1.0"
+
+// searchDocuments selects what search looks at for each problem p: its id,
+// name, notes, tag names and review notes, the last two run together.
+const searchDocuments = `
+		SELECT p.id AS id, p.name AS name, COALESCE(p.notes, '') AS notes,
+			COALESCE((SELECT group_concat(t.name, ' ') FROM problem_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.problem_id = p.id), '') AS tags,
+			COALESCE((SELECT group_concat(r.notes, ' ') FROM reviews r WHERE r.problem_id = p.id AND r.notes <> '' AND r.notes <> '` + LegacyReviewNote + `'), '') AS reviews
+		FROM problems p`
+
+// searchRows inserts the problems_fts rows of the problems p selected by
+// where.
+func searchRows(where string) string {
+	return `
+		INSERT INTO problems_fts (rowid, name, notes, tags, reviews)` + searchDocuments + `
+		WHERE ` + where + `;`
+}
+
+// searchTriggers are the triggers createSearchIndex adds to keep
+// problems_fts in sync with every write.
+var searchTriggers = []string{
+	"problems_fts_insert", "problems_fts_update", "problems_fts_delete",
+	"problem_tags_fts_insert", "problem_tags_fts_delete", "tags_fts_update",
+	"reviews_fts_insert", "reviews_fts_update", "reviews_fts_delete",
+}
+
+// hasFTS5 reports whether SQLite was built with FTS5.
+func hasFTS5(q execer) (bool, error) {
+	var fts5 bool
+	err := q.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&fts5)
+	return fts5, err
+}
+
+// reindexTrigger rebuilds the search row of problem id, an expression over
+// the trigger's new or old row, after event.
+func reindexTrigger(name, event, id string) string {
+	return `
+		CREATE TRIGGER IF NOT EXISTS ` + name + ` AFTER ` + event + ` BEGIN
+			DELETE FROM problems_fts WHERE rowid = ` + id + `;` +
+		searchRows(`p.id = `+id) + `
+		END`
+}
+
+// createSearchIndex builds problems_fts, a full-text index with one row
+// per problem, and the triggers that keep it in sync with every write.
+func createSearchIndex(tx *sql.Tx) error {
+	fts5, err := hasFTS5(tx)
+	if err != nil {
+		return err
+	}
+	if !fts5 {
+		return ErrSearchUnavailable
+	}
+	return execAll(
+		`CREATE VIRTUAL TABLE IF NOT EXISTS problems_fts USING fts5(name, notes, tags, reviews, tokenize = 'porter unicode61')`,
+		`DELETE FROM problems_fts`,
+		searchRows(`1`),
+		reindexTrigger("problems_fts_insert", "INSERT ON problems", "new.id"),
+		reindexTrigger("problems_fts_update", "UPDATE OF name, notes ON problems", "new.id"),
+		`CREATE TRIGGER IF NOT EXISTS problems_fts_delete AFTER DELETE ON problems BEGIN
+			DELETE FROM problems_fts WHERE rowid = old.id;
+		END`,
+		reindexTrigger("problem_tags_fts_insert", "INSERT ON problem_tags", "new.problem_id"),
+		reindexTrigger("problem_tags_fts_delete", "DELETE ON problem_tags", "old.problem_id"),
+		`CREATE TRIGGER IF NOT EXISTS tags_fts_update AFTER UPDATE OF name ON tags BEGIN
+			DELETE FROM problems_fts WHERE rowid IN (SELECT problem_id FROM problem_tags WHERE tag_id = new.id);`+
+			searchRows(`p.id IN (SELECT problem_id FROM problem_tags WHERE tag_id = new.id)`)+`
+		END`,
+		reindexTrigger("reviews_fts_insert", "INSERT ON reviews", "new.problem_id"),
+		reindexTrigger("reviews_fts_update", "UPDATE OF notes ON reviews", "new.problem_id"),
+		reindexTrigger("reviews_fts_delete", "DELETE ON reviews", "old.problem_id"),
+	)(tx)
+}
+
+// suspendSearchIndex lets a build without FTS5 write to a database whose
+// search index a build with it created. It drops the triggers, which would
+// fail every write, and marks create_search_index pending again, so that
+// the next build with FTS5 rebuilds the index these writes leave behind.
+func suspendSearchIndex(db *sql.DB) error {
+	fts5, err := hasFTS5(db)
+	if err != nil || fts5 {
+		return err
+	}
+	return retryBusy(func() error {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		var triggers int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE '%fts%'`).Scan(&triggers); err != nil || triggers == 0 {
+			return err
+		}
+		for _, name := range searchTriggers {
+			if _, err := tx.Exec(`DROP TRIGGER IF EXISTS ` + name); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE name = 'create_search_index'`); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// ftsQuery turns free text into an FTS5 query matching problems that
+// contain every word, or a word starting with it. Words are quoted so that
+// punctuation such as "two-sum" or "O(n)" is not read as query syntax.
+func ftsQuery(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// Search returns the problems matching every word of query in their name,
+// notes, tags or review notes, best match first, with a snippet of the
+// matching text. A limit of 0 returns every match. Without FTS5 or the
+// search index it matches like MemoryStore.Search, without stemming.
+func (s *Store) Search(query string, limit int) ([]models.SearchResult, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+	var indexed int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'problems_fts'`).Scan(&indexed); err != nil {
+		return nil, err
+	}
+	fts5, err := hasFTS5(s.db)
+	if err != nil {
+		return nil, err
+	}
+	if indexed == 0 || !fts5 {
+		return s.likeSearch(query, limit)
+	}
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := s.db.Query(`
+		SELECT rowid, snippet(problems_fts, -1, ?, ?, '…', 12), bm25(problems_fts, `+searchWeights+`) AS rank
+		FROM problems_fts
+		WHERE problems_fts MATCH ?
+		ORDER BY rank
+		LIMIT ?`,
+		HighlightStart, HighlightEnd, match, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("searching for %q: %w", query, err)
+	}
+	defer rows.Close()
+
+	var results []models.SearchResult
+	var ids []int
+	for rows.Next() {
+		var r models.SearchResult
+		if err := rows.Scan(&r.Problem.ID, &r.Snippet, &r.Rank); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+		ids = append(ids, r.Problem.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	problems, err := s.problemsByID(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]models.Problem, len(problems))
+	for _, p := range problems {
+		byID[p.ID] = p
+	}
+	for i := range results {
+		results[i].Problem = byID[results[i].Problem.ID]
+	}
+	return results, nil
+}
+
+// likeSearch is Search for databases without a usable search index. LIKE
+// narrows the problems down to those containing every word, and
+// matchFields ranks them.
+func (s *Store) likeSearch(query string, limit int) ([]models.SearchResult, error) {
+	terms := searchWords(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	var where []string
+	var args []any
+	for _, term := range terms {
+		where = append(where, `(name LIKE ? OR notes LIKE ? OR tags LIKE ? OR reviews LIKE ?)`)
+		for range 4 {
+			args = append(args, "%"+term+"%")
+		}
+	}
+	rows, err := s.db.Query(`
+		SELECT * FROM (`+searchDocuments+`)
+		WHERE `+strings.Join(where, " AND "), args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching for %q: %w", query, err)
+	}
+	defer rows.Close()
+
+	var results []models.SearchResult
+	for rows.Next() {
+		var id int
+		var fields [4]string
+		if err := rows.Scan(&id, &fields[0], &fields[1], &fields[2], &fields[3]); err != nil {
+			return nil, err
+		}
+		if score, snippet := matchFields(fields, terms); score > 0 {
+			results = append(results, models.SearchResult{Problem: models.Problem{ID: id}, Snippet: snippet, Rank: -score})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	results = rankResults(results, limit)
+	ids := make([]int, len(results))
+	for i, r := range results {
+		ids[i] = r.Problem.ID
+	}
+	problems, err := s.problemsByID(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]models.Problem, len(problems))
+	for _, p := range problems {
+		byID[p.ID] = p
+	}
+	for i := range results {
+		results[i].Problem = byID[results[i].Problem.ID]
+	}
+	return results, nil
+}
+
+// matchWeights are searchWeights, for the searches that rank matches
+// themselves.
+var matchWeights = [4]float64{10, 5, 3, 1}
+
+// matchFields scores a problem whose name, notes, tags and review notes are
+// fields: every term must start a word of some field, and each hit counts
+// the weight of its field. It returns a score of 0 if a term is missing,
+// and a snippet of the first field with a hit.
+func matchFields(fields [4]string, terms []string) (score float64, snippet string) {
+	for _, term := range terms {
+		hits := 0.0
+		for i, f := range fields {
+			n := 0
+			for _, w := range searchWords(f) {
+				if strings.HasPrefix(w, term) {
+					n++
+				}
+			}
+			if n > 0 && snippet == "" {
+				snippet = highlight(f, terms)
+			}
+			hits += matchWeights[i] * float64(n)
+		}
+		if hits == 0 {
+			return 0, ""
+		}
+		score += hits
+	}
+	return score, snippet
+}
+
+// rankResults orders results scored by matchFields best first and keeps
+// the first limit of them, or all for a limit of 0.
+func rankResults(results []models.SearchResult, limit int) []models.SearchResult {
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Rank < results[j].Rank })
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// searchWords splits s into lower-case runs of letters and digits.
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// highlight marks the words of text starting with any of terms and cuts it
+// down to about a dozen words around the first one.
+func highlight(text string, terms []string) string {
+	words := strings.Fields(text)
+	first := -1
+	for i, w := range words {
+		for _, sw := range searchWords(w) {
+			if slices.ContainsFunc(terms, func(t string) bool { return strings.HasPrefix(sw, t) }) {
+				words[i] = HighlightStart + w + HighlightEnd
+				if first < 0 {
+					first = i
+				}
+				break
+			}
+		}
+	}
+	start := max(first-4, 0)
+	end := min(start+12, len(words))
+	snippet := strings.Join(words[start:end], " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(words) {
+		snippet += "…"
+	}
+	return snippet
+}
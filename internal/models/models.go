@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Problem represents a single practice item.
 type Problem struct {
@@ -8,18 +11,72 @@ type Problem struct {
 	Name         string    `json:"name"`
 	URL          string    `json:"url"`
 	Notes        string    `json:"notes"`
-	Difficulty   int       `json:"difficulty"`    // Initial difficulty (1-5)
-	Interval     int       `json:"interval"`      // Days until next review
-	EaseFactor   float64   `json:"ease_factor"`   // SM-2 multiplier
+	Difficulty   int       `json:"difficulty"`  // Initial difficulty (1-5)
+	Interval     int       `json:"interval"`    // Days until next review
+	EaseFactor   float64   `json:"ease_factor"` // SM-2 multiplier
 	LastReviewed time.Time `json:"last_reviewed"`
 	NextReview   time.Time `json:"next_review"`
 	Tags         []Tag     `json:"tags,omitempty"`
+	// FSRS memory state, zero until the problem is reviewed under FSRS
+	Stability      float64 `json:"stability"`       // Days until recall drops to 90%
+	FSRSDifficulty float64 `json:"fsrs_difficulty"` // FSRS item difficulty (1-10)
+	// Learning queue position
+	State State `json:"state"`
+	Step  int   `json:"step"` // Index of the current learning/relearning step
+	// Lapses counts failed reviews after graduating; too many make it a leech
+	Lapses    int  `json:"lapses"`
+	Suspended bool `json:"suspended"` // Suspended problems are never due
+	Box       int  `json:"box"`       // Leitner box (1..N), 0 if not placed in a box
+	// Retrievability is the predicted probability of recalling the problem
+	// right now. It is computed by the scheduler on load, not stored.
+	Retrievability float64 `json:"retrievability"`
+}
+
+// LeechTag is the tag added to problems that have lapsed too often.
+const LeechTag = "leech"
+
+// HasTag reports whether the problem carries the named tag, in any case.
+func (p Problem) HasTag(name string) bool {
+	for _, t := range p.Tags {
+		if strings.EqualFold(t.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// State is where a problem is in the learning cycle.
+type State string
+
+const (
+	StateNew        State = "new"        // Added but never reviewed
+	StateLearning   State = "learning"   // Working through the learning steps
+	StateReview     State = "review"     // Graduated, scheduled in days
+	StateRelearning State = "relearning" // Failed a review, working through relearning steps
+)
+
+// InLearning reports whether the problem is scheduled in minutes rather than days.
+func (s State) InLearning() bool {
+	return s == StateNew || s == StateLearning || s == StateRelearning
 }
 
 // Tag represents a category for a problem.
 type Tag struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Preset string `json:"preset,omitempty"` // Scheduler preset for problems with this tag
+}
+
+// Preset is a named set of scheduler options that overrides the defaults
+// for problems whose tags it is assigned to. Zero values and empty strings
+// inherit the default setting.
+type Preset struct {
+	Name            string  `json:"name"`
+	MaxInterval     int     `json:"max_interval"`     // Longest interval in days
+	EaseFloor       float64 `json:"ease_floor"`       // Lowest SM-2 ease
+	TargetRetention float64 `json:"target_retention"` // Recall probability to schedule at
+	LearningSteps   string  `json:"learning_steps"`   // e.g. "10m,1h" or "none"
+	RelearningSteps string  `json:"relearning_steps"` // e.g. "10m" or "none"
 }
 
 // Review represents a single review event for a problem.
@@ -30,13 +87,26 @@ type Review struct {
 	ReviewedAt time.Time `json:"reviewed_at"`
 	Notes      string    `json:"notes"`
 	// Snapshot of algorithm state at time of review
-	Interval   int     `json:"interval"`
-	EaseFactor float64 `json:"ease_factor"`
+	Interval       int     `json:"interval"`
+	EaseFactor     float64 `json:"ease_factor"`
+	Stability      float64 `json:"stability"`
+	FSRSDifficulty float64 `json:"fsrs_difficulty"`
+	// Days the problem was scheduled to wait before this review, and the
+	// study days that actually passed (larger if overdue, smaller if early)
+	ScheduledInterval int `json:"scheduled_interval"`
+	ActualInterval    int `json:"actual_interval"`
+}
+
+// SearchResult is a problem found by a full-text search.
+type SearchResult struct {
+	Problem Problem `json:"problem"`
+	Snippet string  `json:"snippet"` // Matching text with the hits marked
+	Rank    float64 `json:"rank"`    // Lower ranks match better
 }
 
 type ReviewStats struct {
-	TotalReviews      int
-	ReviewsLast7Days  int
-	AverageQuality    float64
-	CountByDifficulty map[int]int
+	TotalReviews      int         `json:"total_reviews"`
+	ReviewsLast7Days  int         `json:"reviews_last_7_days"`
+	AverageQuality    float64     `json:"average_quality"`
+	CountByDifficulty map[int]int `json:"count_by_difficulty"`
 }
@@ -0,0 +1,258 @@
+// Package output renders command results as JSON, JSON Lines, CSV or YAML
+// for scripts. Values go through encoding/json first, so the json tags of
+// the models decide field names and order in every format.
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is an output format chosen with --output.
+type Format string
+
+const (
+	Table Format = "table" // Human-readable text, rendered by each command
+	JSON  Format = "json"
+	JSONL Format = "jsonl" // One JSON value per line, one line per list item
+	CSV   Format = "csv"
+	YAML  Format = "yaml"
+)
+
+// Formats lists the accepted formats, for help texts.
+var Formats = []Format{Table, JSON, JSONL, CSV, YAML}
+
+// ParseFormat reads a format name, ignoring case.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown output format %q (use %s)", s, strings.Join(names, ", "))
+}
+
+// Write renders v to w in format f. Lists become JSON arrays, JSON Lines
+// with one item per line, CSV rows or YAML sequences; anything else is a
+// single value, line or row. Table output is left to the caller.
+func Write(w io.Writer, f Format, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	switch f {
+	case JSON:
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, raw, "", "  "); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		_, err := buf.WriteTo(w)
+		return err
+	case Table:
+		return errors.New("output: tables are rendered by each command")
+	}
+
+	tree, err := decode(raw)
+	if err != nil {
+		return err
+	}
+	switch f {
+	case JSONL:
+		return writeJSONL(w, tree)
+	case CSV:
+		return writeCSV(w, tree)
+	case YAML:
+		return writeYAML(w, tree)
+	}
+	return fmt.Errorf("output: unknown format %q", f)
+}
+
+// A decoded value is nil, a bool, a json.Number, a string, a list of
+// values or an object, which keeps its fields in order unlike a map.
+type (
+	object []field
+	field  struct {
+		key   string
+		value any
+	}
+)
+
+func decode(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return decodeValue(dec)
+}
+
+func decodeValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('['):
+		list := []any{}
+		for dec.More() {
+			v, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		_, err := dec.Token()
+		return list, err
+	case json.Delim('{'):
+		obj := object{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, field{key.(string), v})
+		}
+		_, err := dec.Token()
+		return obj, err
+	}
+	return tok, nil
+}
+
+// items returns the elements of a list, or v alone.
+func items(v any) []any {
+	if list, ok := v.([]any); ok {
+		return list
+	}
+	return []any{v}
+}
+
+func writeJSONL(w io.Writer, v any) error {
+	for _, item := range items(v) {
+		line, err := json.Marshal(toJSON(item))
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toJSON turns a decoded value back into something json.Marshal writes in
+// the same field order.
+func toJSON(v any) any {
+	switch v := v.(type) {
+	case object:
+		var b bytes.Buffer
+		b.WriteByte('{')
+		for i, f := range v {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			key, _ := json.Marshal(f.key)
+			value, _ := json.Marshal(toJSON(f.value))
+			b.Write(key)
+			b.WriteByte(':')
+			b.Write(value)
+		}
+		b.WriteByte('}')
+		return json.RawMessage(b.Bytes())
+	case []any:
+		list := make([]any, len(v))
+		for i, x := range v {
+			list[i] = toJSON(x)
+		}
+		return list
+	}
+	return v
+}
+
+// writeCSV writes one row per item, with a column for every field found in
+// any item, in order of first appearance. Items that are not objects go in
+// a single "value" column.
+func writeCSV(w io.Writer, v any) error {
+	rows := items(v)
+	var columns []string
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		obj, ok := row.(object)
+		if !ok {
+			obj = object{{"value", row}}
+		}
+		for _, f := range obj {
+			if !seen[f.key] {
+				seen[f.key] = true
+				columns = append(columns, f.key)
+			}
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if len(columns) > 0 {
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		obj, ok := row.(object)
+		if !ok {
+			obj = object{{"value", row}}
+		}
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			for _, f := range obj {
+				if f.key == column {
+					record[i] = cell(f.value)
+				}
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// cell formats a field for a CSV column: lists of plain values are joined
+// with semicolons and anything else nested is written as JSON.
+func cell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	case []any:
+		parts := make([]string, len(v))
+		for i, x := range v {
+			switch x.(type) {
+			case object, []any:
+				return compact(v)
+			}
+			parts[i] = cell(x)
+		}
+		return strings.Join(parts, ";")
+	}
+	return compact(v)
+}
+
+func compact(v any) string {
+	raw, _ := json.Marshal(toJSON(v))
+	return string(raw)
+}
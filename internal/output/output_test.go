@@ -0,0 +1,173 @@
+package output_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/output"
+)
+
+type tag struct {
+	Name   string `json:"name"`
+	Preset string `json:"preset,omitempty"`
+}
+
+type problem struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"name"`
+	Ease     float64   `json:"ease"`
+	Due      time.Time `json:"due"`
+	Tags     []tag     `json:"tags"`
+	Aliases  []string  `json:"aliases"`
+	Notes    *string   `json:"notes"`
+	Archived bool      `json:"archived"`
+}
+
+var (
+	notes    = "line one\nline \"two\", with a comma"
+	problems = []problem{
+		{ID: 1, Name: "Two Sum", Ease: 2.5, Due: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC),
+			Tags: []tag{{Name: "array"}, {Name: "dp", Preset: "hard"}}, Aliases: []string{"1", "two-sum"}, Notes: &notes},
+		{ID: 2, Name: "yes", Ease: 1.3, Due: time.Date(2026, 3, 11, 4, 0, 0, 0, time.FixedZone("", 9*3600)),
+			Tags: []tag{}, Aliases: []string{}, Archived: true},
+	}
+)
+
+func TestWrite(t *testing.T) {
+	for _, tt := range []struct {
+		format output.Format
+		value  any
+		want   string
+	}{
+		{output.JSON, problems, `[
+  {
+    "id": 1,
+    "name": "Two Sum",
+    "ease": 2.5,
+    "due": "2026-03-10T12:00:00Z",
+    "tags": [
+      {
+        "name": "array"
+      },
+      {
+        "name": "dp",
+        "preset": "hard"
+      }
+    ],
+    "aliases": [
+      "1",
+      "two-sum"
+    ],
+    "notes": "line one\nline \"two\", with a comma",
+    "archived": false
+  },
+  {
+    "id": 2,
+    "name": "yes",
+    "ease": 1.3,
+    "due": "2026-03-11T04:00:00+09:00",
+    "tags": [],
+    "aliases": [],
+    "notes": null,
+    "archived": true
+  }
+]
+`},
+		{output.JSONL, problems, `{"id":1,"name":"Two Sum","ease":2.5,"due":"2026-03-10T12:00:00Z","tags":[{"name":"array"},{"name":"dp","preset":"hard"}],"aliases":["1","two-sum"],"notes":"line one\nline \"two\", with a comma","archived":false}
+{"id":2,"name":"yes","ease":1.3,"due":"2026-03-11T04:00:00+09:00","tags":[],"aliases":[],"notes":null,"archived":true}
+`},
+		{output.CSV, problems, `id,name,ease,due,tags,aliases,notes,archived
+1,Two Sum,2.5,2026-03-10T12:00:00Z,"[{""name"":""array""},{""name"":""dp"",""preset"":""hard""}]",1;two-sum,"line one
+line ""two"", with a comma",false
+2,yes,1.3,2026-03-11T04:00:00+09:00,,,,true
+`},
+		{output.YAML, problems, `- id: 1
+  name: Two Sum
+  ease: 2.5
+  due: "2026-03-10T12:00:00Z"
+  tags:
+    - name: array
+    - name: dp
+      preset: hard
+  aliases:
+    - "1"
+    - two-sum
+  notes: "line one\nline \"two\", with a comma"
+  archived: false
+- id: 2
+  name: "yes"
+  ease: 1.3
+  due: "2026-03-11T04:00:00+09:00"
+  tags: []
+  aliases: []
+  notes: null
+  archived: true
+`},
+
+		// A single value is one document, line or row.
+		{output.JSONL, tag{Name: "dp"}, "{\"name\":\"dp\"}\n"},
+		{output.CSV, tag{Name: "dp", Preset: "hard"}, "name,preset\ndp,hard\n"},
+		{output.YAML, tag{Name: "dp"}, "name: dp\n"},
+		{output.CSV, []string{"a,b", "c"}, "value\n\"a,b\"\nc\n"},
+		{output.YAML, "/home/me/.recall", "/home/me/.recall\n"},
+
+		// Empty lists stay lists.
+		{output.JSON, []problem{}, "[]\n"},
+		{output.JSONL, []problem{}, ""},
+		{output.CSV, []problem{}, ""},
+		{output.YAML, []problem{}, "[]\n"},
+		{output.YAML, struct{}{}, "{}\n"},
+	} {
+		var b strings.Builder
+		if err := output.Write(&b, tt.format, tt.value); err != nil {
+			t.Errorf("%s of %T: %v", tt.format, tt.value, err)
+			continue
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s of %T: got\n%s\nwant\n%s", tt.format, tt.value, got, tt.want)
+		}
+	}
+
+	if err := output.Write(&strings.Builder{}, output.Table, problems); err == nil {
+		t.Error("table: got no error")
+	}
+}
+
+func TestYAMLStrings(t *testing.T) {
+	for in, want := range map[string]string{
+		"Two Sum":        "Two Sum",
+		"two-sum_2":      "two-sum_2",
+		"":               `""`,
+		"No":             `"No"`,
+		"null":           `"null"`,
+		"3":              `"3"`,
+		"-1":             `"-1"`,
+		"trailing ":      `"trailing "`,
+		"key: value":     `"key: value"`,
+		"# comment":      `"# comment"`,
+		"tab\there":      `"tab\there"`,
+		"Café":           `"Café"`,
+		"[array, hash]":  `"[array, hash]"`,
+		"~/.recall/x.db": `"~/.recall/x.db"`,
+	} {
+		var b strings.Builder
+		if err := output.Write(&b, output.YAML, in); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.String(); got != want+"\n" {
+			t.Errorf("%q: got %s, want %s", in, strings.TrimSuffix(got, "\n"), want)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range output.Formats {
+		if got, err := output.ParseFormat(strings.ToUpper(string(f))); err != nil || got != f {
+			t.Errorf("ParseFormat(%q): got %q, %v", strings.ToUpper(string(f)), got, err)
+		}
+	}
+	if _, err := output.ParseFormat("xml"); err == nil || err.Error() != `unknown output format "xml" (use table, json, jsonl, csv, yaml)` {
+		t.Errorf("ParseFormat(xml): got error %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// writeYAML writes a decoded value as a YAML document in block style.
+func writeYAML(w io.Writer, v any) error {
+	var b strings.Builder
+	switch v := v.(type) {
+	case object:
+		if len(v) == 0 {
+			b.WriteString("{}\n")
+		}
+		yamlObject(&b, v, "")
+	case []any:
+		if len(v) == 0 {
+			b.WriteString("[]\n")
+		}
+		yamlList(&b, v, "")
+	default:
+		b.WriteString(yamlScalar(v) + "\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func yamlObject(b *strings.Builder, obj object, indent string) {
+	for i, f := range obj {
+		if i > 0 {
+			b.WriteString(indent)
+		}
+		yamlField(b, f, indent)
+	}
+}
+
+// yamlField writes "key: value", with nested values on the lines below.
+// The caller has already written the indentation of its first line.
+func yamlField(b *strings.Builder, f field, indent string) {
+	b.WriteString(yamlString(f.key) + ":")
+	switch v := f.value.(type) {
+	case object:
+		if len(v) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteString("\n" + indent + "  ")
+		yamlObject(b, v, indent+"  ")
+	case []any:
+		if len(v) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteString("\n")
+		yamlList(b, v, indent+"  ")
+	default:
+		b.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+func yamlList(b *strings.Builder, list []any, indent string) {
+	for _, item := range list {
+		b.WriteString(indent + "-")
+		switch v := item.(type) {
+		case object:
+			if len(v) == 0 {
+				b.WriteString(" {}\n")
+				continue
+			}
+			b.WriteString(" ")
+			yamlObject(b, v, indent+"  ")
+		case []any:
+			if len(v) == 0 {
+				b.WriteString(" []\n")
+				continue
+			}
+			b.WriteString("\n")
+			yamlList(b, v, indent+"  ")
+		default:
+			b.WriteString(" " + yamlScalar(v) + "\n")
+		}
+	}
+}
+
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		return yamlString(v)
+	}
+	panic(fmt.Sprintf("output: unexpected %T", v))
+}
+
+// yamlString leaves plain words and paths unquoted and double-quotes
+// anything a YAML parser could read as another type or as syntax. Go's
+// quoting escapes are valid in YAML double-quoted strings.
+func yamlString(s string) string {
+	plain := s != "" && (isLetter(s[0]) || s[0] == '_' || s[0] == '/')
+	for i := 0; plain && i < len(s); i++ {
+		plain = isLetter(s[i]) || (s[i] >= '0' && s[i] <= '9') || strings.IndexByte(" _./-", s[i]) >= 0
+	}
+	if plain && s[len(s)-1] != ' ' {
+		switch strings.ToLower(s) {
+		case "true", "false", "yes", "no", "y", "n", "on", "off", "null":
+		default:
+			return s
+		}
+	}
+	return strconv.Quote(s)
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,179 @@
+package query
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+)
+
+// Env is what matching a problem depends on besides the problem itself.
+type Env struct {
+	Now            time.Time
+	Calendar       clock.Calendar
+	LeechThreshold int
+}
+
+// Match reports whether p matches e. It is the reference for stores that
+// compile expressions into queries instead.
+func Match(e Expr, p models.Problem, env Env) bool {
+	switch e := e.(type) {
+	case nil:
+		return true
+	case And:
+		for _, x := range e {
+			if !Match(x, p, env) {
+				return false
+			}
+		}
+		return true
+	case Or:
+		for _, x := range e {
+			if Match(x, p, env) {
+				return true
+			}
+		}
+		return false
+	case Not:
+		return !Match(e.X, p, env)
+	case Tag:
+		return slices.ContainsFunc(p.Tags, func(t models.Tag) bool { return strings.EqualFold(t.Name, string(e)) })
+	case Name:
+		return strings.Contains(strings.ToLower(p.Name), strings.ToLower(string(e)))
+	case Is:
+		return matchIs(e, p, env)
+	case Compare:
+		return compare(fieldValue(e.Field, p), e.Op, e.Value, e.Max)
+	case Days:
+		if e.Field == DateReviewed && p.State == models.StateNew {
+			return false // Never reviewed
+		}
+		t := p.NextReview
+		if e.Field == DateReviewed {
+			t = p.LastReviewed
+		}
+		from, to := e.Bounds(env.Now, env.Calendar)
+		return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+	}
+	panic(fmt.Sprintf("query: unknown expression %T", e))
+}
+
+func matchIs(e Is, p models.Problem, env Env) bool {
+	switch e {
+	case "due":
+		return IsDue(p, env.Now, env.Calendar)
+	case "new":
+		return p.State == models.StateNew
+	case "learning":
+		return p.State == models.StateLearning || p.State == models.StateRelearning
+	case "review":
+		return p.State == models.StateReview
+	case "suspended":
+		return p.Suspended
+	case "leech":
+		return (env.LeechThreshold > 0 && p.Lapses >= env.LeechThreshold) || p.HasTag(models.LeechTag)
+	}
+	return false
+}
+
+// IsDue reports whether p is due now: learning steps once their time has
+// passed, reviews once their day has come. Suspended problems never are.
+func IsDue(p models.Problem, now time.Time, cal clock.Calendar) bool {
+	if p.Suspended {
+		return false
+	}
+	if p.State.InLearning() {
+		return !p.NextReview.After(now)
+	}
+	return p.NextReview.Before(cal.DayEnd(now))
+}
+
+func fieldValue(f Field, p models.Problem) float64 {
+	switch f {
+	case FieldDifficulty:
+		return float64(p.Difficulty)
+	case FieldInterval:
+		return float64(p.Interval)
+	case FieldEase:
+		return p.EaseFactor
+	case FieldLapses:
+		return float64(p.Lapses)
+	case FieldBox:
+		return float64(p.Box)
+	}
+	panic(fmt.Sprintf("query: unknown field %q", f))
+}
+
+func compare(v float64, op Op, value, max float64) bool {
+	switch op {
+	case Less:
+		return v < value
+	case LessEqual:
+		return v <= value
+	case Greater:
+		return v > value
+	case GreaterEqual:
+		return v >= value
+	case Between:
+		return v >= value && v <= max
+	default:
+		return v == value
+	}
+}
+
+// SortRecall orders problems by their predicted recall, which only a
+// scheduler can compute, so stores refuse it with ErrRecallSort.
+const SortRecall = "recall"
+
+// ErrRecallSort is returned by stores asked to sort by recall.
+var ErrRecallSort = errors.New("sorting by recall needs a scheduler")
+
+// SortKeys lists the keys accepted by Sort.
+var SortKeys = []string{"due", SortRecall, "name", "difficulty", "id", "interval", "ease", "lapses", "reviewed"}
+
+// comparators gives each sort key its natural order: soonest due, least
+// likely recalled, hardest, shortest interval, lowest ease, most lapses
+// and least recently reviewed first.
+var comparators = map[string]func(a, b models.Problem) int{
+	"due":        func(a, b models.Problem) int { return a.NextReview.Compare(b.NextReview) },
+	SortRecall:   func(a, b models.Problem) int { return cmp.Compare(a.Retrievability, b.Retrievability) },
+	"name":       byName,
+	"difficulty": func(a, b models.Problem) int { return cmp.Compare(b.Difficulty, a.Difficulty) },
+	"id":         func(a, b models.Problem) int { return cmp.Compare(a.ID, b.ID) },
+	"interval":   func(a, b models.Problem) int { return cmp.Compare(a.Interval, b.Interval) },
+	"ease":       func(a, b models.Problem) int { return cmp.Compare(a.EaseFactor, b.EaseFactor) },
+	"lapses":     func(a, b models.Problem) int { return cmp.Compare(b.Lapses, a.Lapses) },
+	"reviewed":   func(a, b models.Problem) int { return a.LastReviewed.Compare(b.LastReviewed) },
+}
+
+func byName(a, b models.Problem) int {
+	return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+}
+
+// Sort orders problems by key, "" meaning "due", with ties broken by ID.
+// Reverse flips the whole order.
+func Sort(problems []models.Problem, key string, reverse bool) error {
+	if key == "" {
+		key = "due"
+	}
+	byKey, ok := comparators[key]
+	if !ok {
+		return fmt.Errorf("unknown sort key %q (use %s)", key, strings.Join(SortKeys, ", "))
+	}
+	slices.SortStableFunc(problems, func(a, b models.Problem) int {
+		c := byKey(a, b)
+		if c == 0 && key != SortRecall {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if reverse {
+			return -c
+		}
+		return c
+	})
+	return nil
+}
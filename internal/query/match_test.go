@@ -0,0 +1,126 @@
+package query_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+	"github.com/LavenderBridge/spaced-repetition/internal/models"
+	"github.com/LavenderBridge/spaced-repetition/internal/query"
+)
+
+var (
+	now = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	env = query.Env{Now: now, Calendar: clock.Calendar{Location: time.UTC, DayStartHour: 4}}
+)
+
+// problems returns one problem of each kind the filters tell apart, in ID
+// order. Today's study day runs from 04:00 on March 10th to 04:00 on the 11th.
+func problems() []models.Problem {
+	tags := func(names ...string) []models.Tag {
+		var tags []models.Tag
+		for _, n := range names {
+			tags = append(tags, models.Tag{Name: n})
+		}
+		return tags
+	}
+	day := func(days int) time.Time { return now.AddDate(0, 0, days) }
+	return []models.Problem{
+		{ID: 1, Name: "Two Sum", Difficulty: 2, Interval: 6, EaseFactor: 2.6, Box: 2, State: models.StateReview,
+			LastReviewed: day(-7), NextReview: day(-1), Tags: tags("array", "hash")},
+		{ID: 2, Name: "Coin Change", Difficulty: 4, Interval: 10, EaseFactor: 2.2, Lapses: 3, State: models.StateReview,
+			LastReviewed: time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC), NextReview: time.Date(2026, 3, 11, 3, 0, 0, 0, time.UTC),
+			Tags: tags("dp", "Leech")},
+		{ID: 3, Name: "Edit Distance", Difficulty: 5, EaseFactor: 2.5, State: models.StateLearning,
+			LastReviewed: now.Add(-time.Hour), NextReview: now.Add(10 * time.Minute)},
+		{ID: 4, Name: "House Robber", Difficulty: 3, EaseFactor: 2.5, State: models.StateNew,
+			LastReviewed: now.Add(-2 * time.Hour), NextReview: now.Add(-time.Hour)},
+		{ID: 5, Name: "Climbing Stairs", Difficulty: 1, Interval: 20, EaseFactor: 2.5, State: models.StateReview, Suspended: true,
+			LastReviewed: day(-22), NextReview: day(-2)},
+		{ID: 6, Name: "Word Break", Difficulty: 4, Interval: 30, EaseFactor: 2.5, Lapses: 2, State: models.StateReview,
+			LastReviewed: day(-23), NextReview: day(7), Tags: tags("dp")},
+	}
+}
+
+// matchCases are filters with the problems they match.
+var matchCases = []struct {
+	filter    string
+	threshold int
+	want      string
+}{
+	{"", 0, "[Two Sum Coin Change Edit Distance House Robber Climbing Stairs Word Break]"},
+	{"is:due", 0, "[Two Sum Coin Change House Robber]"},
+	{"is:new OR is:suspended", 0, "[House Robber Climbing Stairs]"},
+	{"NOT (is:review OR is:new)", 0, "[Edit Distance]"},
+	{"is:learning", 0, "[Edit Distance]"},
+	{"is:leech", 0, "[Coin Change]"},
+	{"is:leech", 2, "[Coin Change Word Break]"},
+	{"tag:DP", 0, "[Coin Change Word Break]"},
+	{`name:sum OR name:"HOUSE robber"`, 0, "[Two Sum House Robber]"},
+	{"diff:>=4 -tag:leech", 0, "[Edit Distance Word Break]"},
+	{"diff:>=4 OR tag:array is:due", 0, "[Two Sum Coin Change Edit Distance Word Break]"},
+	{"ivl:5..10 ease:<2.5", 0, "[Coin Change]"},
+	{"box:2", 0, "[Two Sum]"},
+	{"lapses:!=0", 0, "[Coin Change Word Break]"},
+	{"due:<0", 0, "[Two Sum Climbing Stairs]"},
+	{"due:0", 0, "[Coin Change Edit Distance House Robber]"},
+	{"due:<7d", 0, "[Two Sum Coin Change Edit Distance House Robber Climbing Stairs]"},
+	{"due:1w", 0, "[Word Break]"},
+	{"reviewed:<=7d", 0, "[Two Sum Edit Distance]"},
+	{"reviewed:10", 0, "[Coin Change]"},
+	{"reviewed:>20", 0, "[Climbing Stairs Word Break]"},
+}
+
+func TestMatch(t *testing.T) {
+	for _, tt := range matchCases {
+		filter, err := query.Parse(tt.filter)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.filter, err)
+		}
+		var names []string
+		for _, p := range problems() {
+			if query.Match(filter, p, query.Env{Now: env.Now, Calendar: env.Calendar, LeechThreshold: tt.threshold}) {
+				names = append(names, p.Name)
+			}
+		}
+		if got := fmt.Sprint(names); got != tt.want {
+			t.Errorf("Match(%q): got %s, want %s", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	for _, tt := range []struct {
+		key     string
+		reverse bool
+		want    string
+	}{
+		{"", false, "[5 1 4 3 2 6]"},
+		{"due", true, "[6 2 3 4 1 5]"},
+		{"name", false, "[5 2 3 4 1 6]"},
+		{"difficulty", false, "[3 2 6 4 1 5]"},
+		{"difficulty", true, "[5 1 4 6 2 3]"},
+		{"id", true, "[6 5 4 3 2 1]"},
+		{"interval", false, "[3 4 1 2 5 6]"},
+		{"ease", false, "[2 3 4 5 6 1]"},
+		{"lapses", false, "[2 6 1 3 4 5]"},
+		{"reviewed", false, "[6 5 2 1 4 3]"},
+	} {
+		ps := problems()
+		if err := query.Sort(ps, tt.key, tt.reverse); err != nil {
+			t.Fatalf("Sort(%q): %v", tt.key, err)
+		}
+		var ids []int
+		for _, p := range ps {
+			ids = append(ids, p.ID)
+		}
+		if got := fmt.Sprint(ids); got != tt.want {
+			t.Errorf("Sort(%q, reverse %v): got %s, want %s", tt.key, tt.reverse, got, tt.want)
+		}
+	}
+
+	if err := query.Sort(problems(), "hardest", false); err == nil {
+		t.Error(`Sort("hardest"): got no error`)
+	}
+}
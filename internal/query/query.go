@@ -0,0 +1,409 @@
+// Package query parses the filter language of list and due, such as
+// "tag:dp diff:>=4 due:<7d", into an expression that stores compile into
+// their own lookups.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/clock"
+)
+
+// Query selects, orders and limits problems.
+type Query struct {
+	Filter  Expr   // nil matches every problem
+	Sort    string // One of SortKeys; "" orders by next review
+	Reverse bool
+	Limit   int // 0 for no limit
+
+	// LeechThreshold is the number of lapses that makes a problem a leech
+	// for is:leech, or 0 to only match problems tagged as leeches.
+	LeechThreshold int
+}
+
+// Expr is a parsed filter. Each kind is a plain value, so stores can
+// translate it into SQL or evaluate it with Match.
+type Expr interface{ isExpr() }
+
+type (
+	// And matches problems matching every expression.
+	And []Expr
+	// Or matches problems matching any expression.
+	Or []Expr
+	// Not matches problems not matching X.
+	Not struct{ X Expr }
+	// Tag matches problems with the tag, ignoring case.
+	Tag string
+	// Name matches problems whose name contains the text, ignoring case.
+	Name string
+	// Is matches problems in one of the States.
+	Is string
+	// Compare matches a numeric field against a value or the range
+	// Value..Max.
+	Compare struct {
+		Field      Field
+		Op         Op
+		Value, Max float64
+	}
+	// Days matches the number of study days from today to a date field,
+	// or from a date field to today for DateReviewed, against a value or
+	// the range Value..Max.
+	Days struct {
+		Field      DateField
+		Op         Op
+		Value, Max int
+	}
+)
+
+func (And) isExpr()     {}
+func (Or) isExpr()      {}
+func (Not) isExpr()     {}
+func (Tag) isExpr()     {}
+func (Name) isExpr()    {}
+func (Is) isExpr()      {}
+func (Compare) isExpr() {}
+func (Days) isExpr()    {}
+
+// Op compares a field with a value.
+type Op string
+
+const (
+	Less         Op = "<"
+	LessEqual    Op = "<="
+	Greater      Op = ">"
+	GreaterEqual Op = ">="
+	Equal        Op = "="
+	Between      Op = ".." // Value..Max, both included
+)
+
+// Field is a numeric problem field.
+type Field string
+
+const (
+	FieldDifficulty Field = "difficulty"
+	FieldInterval   Field = "interval"
+	FieldEase       Field = "ease"
+	FieldLapses     Field = "lapses"
+	FieldBox        Field = "box"
+)
+
+// DateField is a problem date compared in study days.
+type DateField string
+
+const (
+	DateDue      DateField = "due"      // Days until the next review
+	DateReviewed DateField = "reviewed" // Days since the last review
+)
+
+// States lists the values of is:.
+var States = []string{"due", "new", "learning", "review", "suspended", "leech"}
+
+// keys maps each filter key, including aliases, to a parser of its value.
+var keys = map[string]func(value string) (Expr, error){
+	"tag":        func(v string) (Expr, error) { return Tag(v), nil },
+	"name":       func(v string) (Expr, error) { return Name(v), nil },
+	"is":         parseIs,
+	"diff":       compareParser(FieldDifficulty),
+	"difficulty": compareParser(FieldDifficulty),
+	"ivl":        compareParser(FieldInterval),
+	"interval":   compareParser(FieldInterval),
+	"ease":       compareParser(FieldEase),
+	"lapses":     compareParser(FieldLapses),
+	"box":        compareParser(FieldBox),
+	"due":        daysParser(DateDue),
+	"reviewed":   daysParser(DateReviewed),
+}
+
+// Keys lists the filter keys without their aliases, for help texts.
+var Keys = []string{"tag", "name", "is", "diff", "ivl", "ease", "lapses", "box", "due", "reviewed"}
+
+// Parse reads a filter such as `tag:dp (diff:>=4 OR is:leech) -tag:easy`.
+// Terms are joined by AND unless separated by OR; NOT or a leading -
+// negates a term, and parentheses group them. A word without a key
+// matches names containing it. Parse returns nil for an empty filter.
+func Parse(s string) (Expr, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	e, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+	return e, nil
+}
+
+type token struct {
+	text   string
+	quoted bool // Quotes make OR, NOT and parentheses plain words
+}
+
+// tokenize splits s into words and parentheses. Double quotes keep spaces
+// in a word, as in name:"two sum", and are removed.
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, token{text: string(c)})
+			i++
+		default:
+			var b strings.Builder
+			quoted := false
+			for i < len(s) && !strings.ContainsRune(" \t\n()", rune(s[i])) {
+				if s[i] != '"' {
+					b.WriteByte(s[i])
+					i++
+					continue
+				}
+				end := strings.IndexByte(s[i+1:], '"')
+				if end < 0 {
+					return nil, errors.New("unterminated quote")
+				}
+				b.WriteString(s[i+1 : i+1+end])
+				i += end + 2
+				quoted = true
+			}
+			tokens = append(tokens, token{text: b.String(), quoted: quoted})
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// keyword reports whether the next token is the unquoted word kw, and
+// consumes it if so.
+func (p *parser) keyword(kw string) bool {
+	if t, ok := p.peek(); ok && !t.quoted && t.text == kw {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) or() (Expr, error) {
+	var terms Or
+	for {
+		e, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, e)
+		if !p.keyword("OR") {
+			break
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *parser) and() (Expr, error) {
+	var terms And
+	for {
+		t, ok := p.peek()
+		if !ok || (!t.quoted && (t.text == ")" || t.text == "OR")) {
+			break
+		}
+		p.keyword("AND")
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, e)
+	}
+	switch len(terms) {
+	case 0:
+		if t, ok := p.peek(); ok {
+			return nil, fmt.Errorf("expected a filter before %q", t.text)
+		}
+		return nil, errors.New("expected a filter at the end")
+	case 1:
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *parser) unary() (Expr, error) {
+	if p.keyword("NOT") || p.keyword("-") {
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{e}, nil
+	}
+	if p.keyword("(") {
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword(")") {
+			return nil, errors.New("missing )")
+		}
+		return e, nil
+	}
+
+	t, ok := p.peek()
+	if !ok {
+		return nil, errors.New("expected a filter at the end")
+	}
+	p.pos++
+	if !t.quoted && len(t.text) > 1 && t.text[0] == '-' {
+		e, err := term(t.text[1:])
+		if err != nil {
+			return nil, err
+		}
+		return Not{e}, nil
+	}
+	return term(t.text)
+}
+
+// term parses a single key:value filter or name word.
+func term(word string) (Expr, error) {
+	key, value, ok := strings.Cut(word, ":")
+	if !ok {
+		return Name(word), nil
+	}
+	parse, known := keys[strings.ToLower(key)]
+	if !known {
+		return nil, fmt.Errorf("unknown filter %q (use %s)", key+":", strings.Join(Keys, ":, ")+":")
+	}
+	if value == "" {
+		return nil, fmt.Errorf("%s: needs a value", key)
+	}
+	return parse(value)
+}
+
+func parseIs(value string) (Expr, error) {
+	for _, s := range States {
+		if strings.EqualFold(value, s) {
+			return Is(s), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown state is:%s (use %s)", value, strings.Join(States, ", "))
+}
+
+// parseRange reads "3", "=3", ">=3", "<3", "2..4" and so on. A != is
+// returned as Equal with negate set.
+func parseRange(s string) (op Op, value, max string, negate bool) {
+	if lo, hi, ok := strings.Cut(s, ".."); ok {
+		return Between, lo, hi, false
+	}
+	for _, o := range []string{"!=", "<=", ">=", "<", ">", "="} {
+		if rest, ok := strings.CutPrefix(s, o); ok {
+			if o == "!=" {
+				return Equal, rest, "", true
+			}
+			return Op(o), rest, "", false
+		}
+	}
+	return Equal, s, "", false
+}
+
+func compareParser(field Field) func(string) (Expr, error) {
+	return func(s string) (Expr, error) {
+		op, lo, hi, negate := parseRange(s)
+		c := Compare{Field: field, Op: op}
+		var err error
+		if c.Value, err = strconv.ParseFloat(lo, 64); err == nil && op == Between {
+			c.Max, err = strconv.ParseFloat(hi, 64)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: want a number like 3, >=3 or 2..4", field, s)
+		}
+		return maybeNot(c, negate), nil
+	}
+}
+
+func daysParser(field DateField) func(string) (Expr, error) {
+	return func(s string) (Expr, error) {
+		op, lo, hi, negate := parseRange(s)
+		d := Days{Field: field, Op: op}
+		var err error
+		if d.Value, err = parseDays(lo); err == nil && op == Between {
+			d.Max, err = parseDays(hi)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: want days like 7d, <2w or 0..3", field, s)
+		}
+		return maybeNot(d, negate), nil
+	}
+}
+
+// parseDays reads a number of days with an optional d, or w for weeks.
+func parseDays(s string) (int, error) {
+	unit := 1
+	if rest, ok := strings.CutSuffix(s, "w"); ok {
+		s, unit = rest, 7
+	} else {
+		s = strings.TrimSuffix(s, "d")
+	}
+	n, err := strconv.Atoi(s)
+	return n * unit, err
+}
+
+func maybeNot(e Expr, negate bool) Expr {
+	if negate {
+		return Not{e}
+	}
+	return e
+}
+
+// Bounds returns the moments between which the date field must fall,
+// from included and to excluded, with a zero time for an open end.
+func (d Days) Bounds(now time.Time, cal clock.Calendar) (from, to time.Time) {
+	op, value, max := d.Op, d.Value, d.Max
+	if d.Field == DateReviewed {
+		// Days since a review are days until it, negated.
+		flipped := map[Op]Op{Less: Greater, LessEqual: GreaterEqual, Greater: Less, GreaterEqual: LessEqual}
+		if f, ok := flipped[op]; ok {
+			op = f
+		}
+		value, max = -value, -max
+		if op == Between {
+			value, max = max, value
+		}
+	}
+
+	today := cal.DayStart(now)
+	day := func(n int) time.Time { return today.AddDate(0, 0, n) }
+	switch op {
+	case Less:
+		return time.Time{}, day(value)
+	case LessEqual:
+		return time.Time{}, day(value + 1)
+	case Greater:
+		return day(value + 1), time.Time{}
+	case GreaterEqual:
+		return day(value), time.Time{}
+	case Between:
+		return day(value), day(max + 1)
+	default:
+		return day(value), day(value + 1)
+	}
+}
@@ -0,0 +1,76 @@
+package query_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/LavenderBridge/spaced-repetition/internal/query"
+)
+
+func TestParse(t *testing.T) {
+	a, b, c := query.Name("a"), query.Name("b"), query.Name("c")
+	for _, tt := range []struct {
+		in   string
+		want query.Expr
+	}{
+		{"", nil},
+		{"  ", nil},
+		{"a", a},
+		{"a b", query.And{a, b}},
+		{"a AND b", query.And{a, b}},
+		{"a b OR c", query.Or{query.And{a, b}, c}},
+		{"a OR b c", query.Or{a, query.And{b, c}}},
+		{"a (b OR c)", query.And{a, query.Or{b, c}}},
+		{"NOT a b", query.And{query.Not{a}, b}},
+		{"-a OR b", query.Or{query.Not{a}, b}},
+		{"- (a OR b) c", query.And{query.Not{query.Or{a, b}}, c}},
+		{"NOT NOT a", query.Not{query.Not{a}}},
+		{"a or b", query.And{a, query.Name("or"), b}},
+		{`"OR" "(a)"`, query.And{query.Name("OR"), query.Name("(a)")}},
+		{`name:"two sum"`, query.Name("two sum")},
+		{`"two sum"`, query.Name("two sum")},
+		{"tag:dp -tag:easy", query.And{query.Tag("dp"), query.Not{query.Tag("easy")}}},
+		{"TAG:DP", query.Tag("DP")},
+		{"is:Due", query.Is("due")},
+		{"diff:4", query.Compare{Field: query.FieldDifficulty, Op: query.Equal, Value: 4}},
+		{"diff:>=4", query.Compare{Field: query.FieldDifficulty, Op: query.GreaterEqual, Value: 4}},
+		{"difficulty:<3", query.Compare{Field: query.FieldDifficulty, Op: query.Less, Value: 3}},
+		{"ivl:2..10", query.Compare{Field: query.FieldInterval, Op: query.Between, Value: 2, Max: 10}},
+		{"ease:!=2.5", query.Not{query.Compare{Field: query.FieldEase, Op: query.Equal, Value: 2.5}}},
+		{"lapses:>2", query.Compare{Field: query.FieldLapses, Op: query.Greater, Value: 2}},
+		{"box:=3", query.Compare{Field: query.FieldBox, Op: query.Equal, Value: 3}},
+		{"due:<7d", query.Days{Field: query.DateDue, Op: query.Less, Value: 7}},
+		{"due:0", query.Days{Field: query.DateDue, Op: query.Equal}},
+		{"due:<=-1", query.Days{Field: query.DateDue, Op: query.LessEqual, Value: -1}},
+		{"reviewed:1w..2w", query.Days{Field: query.DateReviewed, Op: query.Between, Value: 7, Max: 14}},
+	} {
+		got, err := query.Parse(tt.in)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q): got %#v, %v, want %#v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{`name:"two sum`, "unterminated quote"},
+		{"(a OR b", "missing )"},
+		{"a)", `unexpected ")"`},
+		{"a OR", "expected a filter at the end"},
+		{"NOT", "expected a filter at the end"},
+		{"OR a", `expected a filter before "OR"`},
+		{"()", `expected a filter before ")"`},
+		{"foo:bar", `unknown filter "foo:" (use tag:, name:, is:, diff:, ivl:, ease:, lapses:, box:, due:, reviewed:)`},
+		{"diff:", "diff: needs a value"},
+		{"is:hard", "unknown state is:hard (use due, new, learning, review, suspended, leech)"},
+		{"diff:>=x", `invalid difficulty ">=x": want a number like 3, >=3 or 2..4`},
+		{"ivl:2..", `invalid interval "2..": want a number like 3, >=3 or 2..4`},
+		{"due:soon", `invalid due "soon": want days like 7d, <2w or 0..3`},
+		{"reviewed:1.5d", `invalid reviewed "1.5d": want days like 7d, <2w or 0..3`},
+	} {
+		_, err := query.Parse(tt.in)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Parse(%q): got error %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}